@@ -0,0 +1,80 @@
+package view
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/alecthomas/kong"
+	"github.com/nkzk/xrefs/internal/diagnose"
+	"github.com/nkzk/xrefs/internal/k8s"
+)
+
+// DiagnoseCmd groups the commands that report on problems in a resource tree.
+type DiagnoseCmd struct {
+	Stuck StuckCmd `cmd:"" help:"report resources with stuck deletions and what is blocking them"`
+	Why   WhyCmd   `cmd:"" help:"find the resources that keep a resource from becoming Ready"`
+}
+
+type StuckCmd struct {
+	Target `embed:""`
+
+	Threshold time.Duration `default:"5m" help:"report resources that have been deleting for longer than this"`
+}
+
+func (c *StuckCmd) Help() string {
+	return `
+	This command loads the whole tree of the targeted resource and reports resources whose deletion has been pending for longer than the threshold, with their remaining finalizers, the Crossplane Usages protecting them and the children that still exist
+
+	Example usage:
+	  xrefs diagnose stuck my-xr.v1alpha1.example.io/name --threshold 10m
+	`
+}
+
+func (c *StuckCmd) Run(k *kong.Context) error {
+	ctx := context.Background()
+
+	kClient, _, root, err := c.resolve(ctx)
+	if err != nil {
+		return err
+	}
+
+	if err := k8s.LoadTree(ctx, root, kClient, -1); err != nil {
+		return fmt.Errorf("cannot load resource tree: %w", err)
+	}
+
+	now := time.Now()
+	fmt.Fprint(k.Stdout, diagnose.StuckReport(diagnose.StuckDeletions(root, c.Threshold, now), c.Threshold, now))
+
+	return nil
+}
+
+type WhyCmd struct {
+	Target `embed:""`
+}
+
+func (c *WhyCmd) Help() string {
+	return `
+	This command loads the whole tree of the targeted resource and finds the deepest unhealthy resources whose own children are healthy, ranked by how long they have been failing
+
+	Example usage:
+	  xrefs diagnose why my-xr.v1alpha1.example.io/name
+	`
+}
+
+func (c *WhyCmd) Run(k *kong.Context) error {
+	ctx := context.Background()
+
+	kClient, _, root, err := c.resolve(ctx)
+	if err != nil {
+		return err
+	}
+
+	if err := k8s.LoadTree(ctx, root, kClient, -1); err != nil {
+		return fmt.Errorf("cannot load resource tree: %w", err)
+	}
+
+	fmt.Fprint(k.Stdout, diagnose.RootCauseReport(root, diagnose.RootCauses(root), time.Now()))
+
+	return nil
+}
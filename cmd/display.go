@@ -0,0 +1,67 @@
+package view
+
+import (
+	"fmt"
+	"log"
+	"os"
+
+	"github.com/nkzk/xrefs/internal/config"
+	"github.com/nkzk/xrefs/internal/k9s"
+	"github.com/nkzk/xrefs/internal/ui"
+)
+
+// Display holds the flags configuring how the tui looks. It is embedded by
+// the commands running the tui.
+type Display struct {
+	Config  string   `default:"" help:"path of the config file, defaults to $XDG_CONFIG_HOME/xrefs/config.yaml" name:"config" type:"path"`
+	Theme   string   `default:"" help:"colour theme: dark, light, high-contrast, k9s, or the name or path of a theme file" name:"theme"`
+	Columns []string `help:"columns to show after the tree, e.g. NAMESPACE,READY,AGE,cond:Healthy,EXTERNAL:.metadata.annotations.crossplane\\.io/external-name" name:"columns" sep:","`
+}
+
+// theme resolves the theme set by flag or config. A k9s skin that cannot be
+// read falls back to the dark theme, since k9s may not be installed.
+func (c *Display) theme(settings config.Config) (config.Theme, error) {
+	name := settings.Theme
+	if c.Theme != "" {
+		name = c.Theme
+	}
+
+	if name != "k9s" {
+		return config.LoadTheme(name)
+	}
+
+	base, err := config.LoadTheme("")
+	if err != nil || os.Getenv("NO_COLOR") != "" {
+		return base, err
+	}
+
+	theme, err := k9s.SkinTheme(base)
+	if err != nil {
+		log.Printf("using the default theme: %v", err)
+	}
+	return theme, nil
+}
+
+// settings loads the config file and applies the flags overriding it.
+func (c *Display) settings() (config.Config, error) {
+	settings, err := config.Load(c.Config)
+	if err != nil {
+		return config.Config{}, err
+	}
+
+	if len(c.Columns) > 0 {
+		// columns given on the command line apply to every kind of root
+		settings.Columns = c.Columns
+		settings.Views = nil
+	}
+
+	if err := settings.Validate(); err != nil {
+		return config.Config{}, fmt.Errorf("--columns: %w", err)
+	}
+
+	if err := ui.ValidateKeys(settings.Keys); err != nil {
+		return config.Config{}, fmt.Errorf("config keys: %w", err)
+	}
+
+	return settings, nil
+}
@@ -0,0 +1,134 @@
+package view
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	tea "charm.land/bubbletea/v2"
+	"github.com/alecthomas/kong"
+	"github.com/nkzk/xrefs/internal/k8s"
+	"github.com/nkzk/xrefs/internal/models"
+	"github.com/nkzk/xrefs/internal/ui"
+)
+
+// overviewTypes are listed in the overview besides the composite and claim
+// categories of Crossplane. Types the cluster does not serve are skipped.
+var overviewTypes = []string{
+	"kustomizations.kustomize.toolkit.fluxcd.io",
+	"applications.argoproj.io",
+}
+
+// overviewLoads is the number of trees loaded at the same time.
+const overviewLoads = 4
+
+type OverviewCmd struct {
+	Cluster `embed:""`
+	Display `embed:""`
+
+	StuckThreshold time.Duration `default:"5m" help:"deletions pending for longer than this are reported as stuck" name:"stuck-threshold"`
+	ExpandDepth    int           `default:"1" help:"number of levels below a root to expand when it is opened, use --expand-depth=-1 to expand the whole tree" name:"expand-depth"`
+}
+
+func (c *OverviewCmd) Help() string {
+	return `
+	This command lists every Crossplane composite resource and claim, Flux Kustomization and Argo CD Application in the cluster with the health of their whole trees. Selecting a row opens it in the tree view, and going back from its root returns to the overview
+
+	Example usage:
+	  xrefs overview
+	  xrefs overview -n my-namespace
+	`
+}
+
+func (c *OverviewCmd) Run(k *kong.Context) error {
+	ctx := context.Background()
+
+	settings, err := c.settings()
+	if err != nil {
+		return err
+	}
+
+	theme, err := c.theme(settings)
+	if err != nil {
+		return err
+	}
+
+	r, err := c.newResolver()
+	if err != nil {
+		return err
+	}
+
+	view := &Cmd{
+		Target:         Target{Cluster: c.Cluster},
+		StuckThreshold: c.StuckThreshold,
+		ExpandDepth:    c.ExpandDepth,
+		Display:        c.Display,
+	}
+
+	sem := make(chan struct{}, overviewLoads)
+
+	// loads are cancelled when the overview is left, loads of a previous
+	// run that start late see the cancelled context
+	var (
+		mu      sync.Mutex
+		loadCtx context.Context
+	)
+	overview := ui.NewOverview(ui.OverviewConfig{
+		Config: ui.Config{
+			Settings: settings,
+			Theme:    &theme,
+		},
+		List: func() ([]*models.Resource, error) {
+			return r.overview(ctx, c.Namespace)
+		},
+		Load: func(root *models.Resource) (*models.Resource, error) {
+			mu.Lock()
+			loadCtx := loadCtx
+			mu.Unlock()
+
+			select {
+			case sem <- struct{}{}:
+				defer func() { <-sem }()
+			case <-loadCtx.Done():
+				return nil, loadCtx.Err()
+			}
+
+			tree := models.NewResource(nil, root.Unstructured, root.Ref)
+			tree.ID = root.ID
+			tree.Expanded = true
+			if err := k8s.LoadTree(loadCtx, tree, r.kClient, -1); err != nil {
+				return nil, err
+			}
+			tree.Expanded = false // shown as a single row
+
+			return tree, nil
+		},
+	})
+
+	for {
+		mu.Lock()
+		var cancel context.CancelFunc
+		loadCtx, cancel = context.WithCancel(ctx)
+		mu.Unlock()
+
+		m, err := tea.NewProgram(overview, tea.WithOutput(k.Stdout)).Run()
+		cancel() // trees still loading are not shown anymore
+		if err != nil {
+			return err
+		}
+
+		overview = m.(ui.Overview)
+		selected := overview.Selected()
+		if selected == nil {
+			return nil
+		}
+
+		root := models.NewResource(nil, selected.Unstructured, selected.Ref)
+		root.Expanded = true
+
+		back, err := view.watchResourceTrees(ctx, k, r, []*models.Resource{root}, settings, theme)
+		if err != nil || !back {
+			return err
+		}
+	}
+}
@@ -0,0 +1,459 @@
+package view
+
+import (
+	"cmp"
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+
+	"github.com/nkzk/xrefs/internal/k8s"
+	"github.com/nkzk/xrefs/internal/models"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/meta"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/client-go/discovery"
+	"k8s.io/client-go/tools/clientcmd"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// resolver looks up roots given as TYPE[.VERSION][.GROUP][/NAME], reusing the
+// clients set up for the first root so opening another root from the tui
+// does not rebuild the discovery client.
+type resolver struct {
+	mock      bool
+	namespace string // namespace of roots that do not set one, the --namespace flag
+
+	kClient      k8s.Client
+	watcher      k8s.ResourceWatcher
+	clientconfig clientcmd.ClientConfig
+	client       client.Client
+	rmapper      meta.RESTMapper
+	discovery    discovery.DiscoveryInterface
+
+	mu    sync.Mutex
+	types []string // resource types for completion, discovered on first use
+}
+
+// errNoName is returned when a root is given by its type only, so it can be
+// picked from the objects of the type instead.
+var errNoName = errors.New("a name is required, use TYPE[.VERSION][.GROUP]/NAME")
+
+// newResolver sets up the clients of the cluster.
+func (t *Cluster) newResolver() (*resolver, error) {
+	if t.Mock {
+		return &resolver{
+			mock:      true,
+			namespace: t.Namespace,
+			kClient:   k8s.NewMockClient(),
+			watcher:   k8s.NewMockResourceWatcher(),
+		}, nil
+	}
+
+	clientconfig, client, rmapper, d, err := k8s.SetupKubeClient(t.KubeConfig, t.Context, t.CacheOnDisk)
+	if err != nil {
+		return nil, err
+	}
+
+	return &resolver{
+		namespace:    t.Namespace,
+		kClient:      k8s.NewK8sClient(client),
+		watcher:      k8s.NewKubernetesResourceWatcher(client),
+		clientconfig: clientconfig,
+		client:       client,
+		rmapper:      rmapper,
+		discovery:    d,
+	}, nil
+}
+
+// open fetches the root resource.
+func (r *resolver) open(ctx context.Context, resource, name, namespace string) (*models.Resource, error) {
+	if r.mock {
+		if resource == "composite" {
+			return nil, errNoName
+		}
+		return r.openMock(ctx, resource)
+	}
+
+	resource, name, err := k8s.ParseResourceName(resource, name)
+	if err != nil {
+		return nil, err
+	}
+
+	if name == "" {
+		return nil, errNoName
+	}
+
+	if namespace == "" {
+		namespace = r.namespace
+	}
+
+	resourceMapping, err := k8s.MappingFor(r.rmapper, resource)
+	if err != nil {
+		return nil, err
+	}
+
+	resourceObjectRef, err := k8s.ResourceObjectRefFromMapping(
+		resourceMapping,
+		r.clientconfig,
+		name,
+		namespace,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	root, err := r.kClient.GetUnstructured(ctx, resourceObjectRef)
+	if err != nil {
+		return nil, err
+	}
+
+	rootResource := models.NewResource(
+		nil,
+		root,
+		resourceObjectRef,
+	)
+	rootResource.Expanded = true
+
+	return rootResource, nil
+}
+
+func (r *resolver) openMock(ctx context.Context, resource string) (*models.Resource, error) {
+	rootRef := &corev1.ObjectReference{
+		APIVersion: "example.io/v1alpha1",
+		Kind:       "MyXR",
+		Name:       "example",
+		Namespace:  "default",
+	}
+
+	if strings.HasPrefix(resource, "kustomization") {
+		rootRef = &corev1.ObjectReference{
+			APIVersion: "kustomize.toolkit.fluxcd.io/v1",
+			Kind:       "Kustomization",
+			Name:       "example",
+			Namespace:  "default",
+		}
+	}
+
+	root, err := r.kClient.GetUnstructured(ctx, rootRef)
+	if err != nil {
+		return nil, err
+	}
+
+	rootResource := models.NewResource(
+		nil,
+		root,
+		rootRef,
+	)
+	rootResource.Expanded = true
+
+	return rootResource, nil
+}
+
+// candidates lists the objects a root can be picked from: the objects of a
+// resource type, or of all types in a category like `composite`. They are
+// listed in namespace, or across all namespaces when none is set.
+func (r *resolver) candidates(ctx context.Context, resource, namespace string) ([]*models.Resource, error) {
+	if r.mock {
+		return r.mockCandidates(ctx)
+	}
+
+	if namespace == "" {
+		namespace = r.namespace
+	}
+
+	types := k8s.CategoryTypes(r.discovery, resource)
+	if len(types) == 0 {
+		mapping, err := k8s.MappingFor(r.rmapper, resource)
+		if err != nil {
+			return nil, err
+		}
+		return k8s.ListResources(ctx, r.client, mapping, namespace)
+	}
+
+	return r.listTypes(ctx, types, namespace)
+}
+
+// selection returns the synthetic root of the objects of a resource type, or
+// of all types in a category, that match a label selector. They are listed in
+// the default namespace, or in all namespaces.
+func (r *resolver) selection(resource, selector string, allNamespaces bool) (*models.Resource, error) {
+	if _, err := labels.Parse(selector); err != nil {
+		return nil, fmt.Errorf("invalid selector %q: %w", selector, err)
+	}
+
+	namespace := ""
+	if !allNamespaces {
+		namespace = r.defaultNamespace()
+	}
+
+	name := resource
+	if selector != "" {
+		name += " -l " + selector
+	}
+	if allNamespaces {
+		name += " -A"
+	}
+
+	if r.mock {
+		types := []corev1.ObjectReference{{APIVersion: "example.io/v1alpha1", Kind: "MyXR", Namespace: namespace}}
+		root := k8s.NewSelection(name, namespace, selector, types)
+		root.Expanded = true
+		return root, nil
+	}
+
+	resources := k8s.CategoryTypes(r.discovery, resource)
+	if len(resources) == 0 {
+		resources = []string{resource}
+	}
+
+	types := make([]corev1.ObjectReference, 0, len(resources))
+	for _, res := range resources {
+		mapping, err := k8s.MappingFor(r.rmapper, res)
+		if err != nil {
+			return nil, err
+		}
+
+		t := corev1.ObjectReference{
+			APIVersion: mapping.GroupVersionKind.GroupVersion().String(),
+			Kind:       mapping.GroupVersionKind.Kind,
+		}
+		if mapping.Scope.Name() == meta.RESTScopeNameNamespace {
+			t.Namespace = namespace
+		}
+		types = append(types, t)
+	}
+
+	root := k8s.NewSelection(name, namespace, selector, types)
+	root.Expanded = true
+
+	return root, nil
+}
+
+// defaultNamespace returns the namespace of the --namespace flag, or the one
+// of the kubeconfig context.
+func (r *resolver) defaultNamespace() string {
+	if r.mock {
+		return cmp.Or(r.namespace, "default")
+	}
+
+	if r.namespace != "" {
+		return r.namespace
+	}
+
+	namespace, _, _ := r.clientconfig.Namespace()
+	return namespace
+}
+
+// overview lists the roots of the overview: the composite resources and
+// claims of Crossplane, and the overview types served by the cluster.
+func (r *resolver) overview(ctx context.Context, namespace string) ([]*models.Resource, error) {
+	if r.mock {
+		return r.mockCandidates(ctx)
+	}
+
+	if namespace == "" {
+		namespace = r.namespace
+	}
+
+	types := append(k8s.CategoryTypes(r.discovery, "composite"), k8s.CategoryTypes(r.discovery, "claim")...)
+	for _, t := range overviewTypes {
+		if _, err := k8s.MappingFor(r.rmapper, t); err == nil {
+			types = append(types, t)
+		}
+	}
+
+	return r.listTypes(ctx, types, namespace)
+}
+
+// listTypes lists the objects of many types, some of which may not be
+// readable. An error is only returned when none could be listed.
+func (r *resolver) listTypes(ctx context.Context, types []string, namespace string) ([]*models.Resource, error) {
+	var (
+		resources []*models.Resource
+		errs      []error
+	)
+	for _, t := range types {
+		mapping, err := k8s.MappingFor(r.rmapper, t)
+		if err != nil {
+			errs = append(errs, err)
+			continue
+		}
+
+		listed, err := k8s.ListResources(ctx, r.client, mapping, namespace)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("cannot list %s: %w", t, err))
+			continue
+		}
+		resources = append(resources, listed...)
+	}
+
+	if len(resources) == 0 && len(errs) > 0 {
+		return nil, errors.Join(errs...)
+	}
+
+	return resources, nil
+}
+
+func (r *resolver) mockCandidates(ctx context.Context) ([]*models.Resource, error) {
+	var candidates []*models.Resource
+	for _, resource := range []string{"myxr", "kustomization"} {
+		root, err := r.openMock(ctx, resource)
+		if err != nil {
+			return nil, err
+		}
+		root.Conditions = k8s.ParseConditions(root.Unstructured)
+		candidates = append(candidates, root)
+	}
+
+	return candidates, nil
+}
+
+// query opens the root typed in the command prompt of the tui, like
+// `TYPE[.VERSION][.GROUP][/NAME] [NAME] [-n NAMESPACE]`.
+func (r *resolver) query(ctx context.Context, q string) (*models.Resource, error) {
+	resource, name, namespace, err := parseQuery(q)
+	if err != nil {
+		return nil, err
+	}
+
+	return r.open(ctx, resource, name, namespace)
+}
+
+// parseQuery splits a query into the resource, name and namespace arguments.
+func parseQuery(q string) (resource, name, namespace string, err error) {
+	args, namespace, err := splitQuery(strings.Fields(q))
+	if err != nil {
+		return "", "", "", err
+	}
+
+	switch len(args) {
+	case 0:
+		return "", "", "", errors.New("missing resource, use TYPE[.VERSION][.GROUP]/NAME")
+	case 1:
+		return args[0], "", namespace, nil
+	case 2:
+		return args[0], args[1], namespace, nil
+	default:
+		return "", "", "", fmt.Errorf("unexpected argument %q", args[2])
+	}
+}
+
+// splitQuery splits the fields of a query into its arguments and the
+// namespace flag, which may be given anywhere.
+func splitQuery(fields []string) (args []string, namespace string, err error) {
+	for i := 0; i < len(fields); i++ {
+		f := fields[i]
+		switch {
+		case f == "-n" || f == "--namespace":
+			if i+1 == len(fields) {
+				return args, namespace, fmt.Errorf("%s needs a namespace", f)
+			}
+			i++
+			namespace = fields[i]
+		case strings.HasPrefix(f, "-n="), strings.HasPrefix(f, "--namespace="):
+			_, namespace, _ = strings.Cut(f, "=")
+		case strings.HasPrefix(f, "-"):
+			return args, namespace, fmt.Errorf("unknown flag %s", f)
+		default:
+			args = append(args, f)
+		}
+	}
+
+	return args, namespace, nil
+}
+
+// complete returns the completions of the last word of a query, each
+// prefixed with the rest of the query. Names are listed in the namespace
+// given anywhere in the query.
+func (r *resolver) complete(ctx context.Context, input string) []string {
+	fields := strings.Fields(input)
+
+	word := ""
+	if len(fields) > 0 && !strings.HasSuffix(input, " ") {
+		word = fields[len(fields)-1]
+		fields = fields[:len(fields)-1]
+	}
+	prefix := input[:len(input)-len(word)]
+
+	if len(fields) > 0 && (fields[len(fields)-1] == "-n" || fields[len(fields)-1] == "--namespace") {
+		return matching(prefix, word, r.namespaces(ctx))
+	}
+	if strings.HasPrefix(word, "-") {
+		return nil
+	}
+
+	args, namespace, _ := splitQuery(fields)
+
+	var candidates []string
+	switch {
+	case len(args) == 0 && !strings.Contains(word, "/"):
+		candidates = r.resourceTypes()
+
+	case len(args) == 0:
+		resource, _, _ := strings.Cut(word, "/")
+		for _, name := range r.names(ctx, resource, namespace) {
+			candidates = append(candidates, resource+"/"+name)
+		}
+
+	case len(args) == 1 && !strings.Contains(args[0], "/") && !strings.Contains(word, "/"):
+		candidates = r.names(ctx, args[0], namespace)
+	}
+
+	return matching(prefix, word, candidates)
+}
+
+// matching returns the candidates starting with word, prefixed with prefix.
+func matching(prefix, word string, candidates []string) []string {
+	var completions []string
+	for _, c := range candidates {
+		if strings.HasPrefix(strings.ToLower(c), strings.ToLower(word)) {
+			completions = append(completions, prefix+c)
+		}
+	}
+
+	return completions
+}
+
+// resourceTypes returns the resource types of the cluster.
+func (r *resolver) resourceTypes() []string {
+	if r.mock {
+		return []string{"kustomization", "myxr"}
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if r.types == nil {
+		r.types = k8s.ResourceTypes(r.discovery)
+	}
+
+	return r.types
+}
+
+// names returns the names of the objects of a resource type in namespace, or
+// in the default namespace when it is empty.
+func (r *resolver) names(ctx context.Context, resource, namespace string) []string {
+	if r.mock {
+		return []string{"example"}
+	}
+
+	mapping, err := k8s.MappingFor(r.rmapper, resource)
+	if err != nil {
+		return nil
+	}
+
+	names, _ := k8s.ObjectNames(ctx, r.client, mapping, cmp.Or(namespace, r.defaultNamespace()))
+	return names
+}
+
+// namespaces returns the namespaces of the cluster.
+func (r *resolver) namespaces(ctx context.Context) []string {
+	if r.mock {
+		return []string{"default"}
+	}
+
+	names, _ := k8s.NamespaceNames(ctx, r.client)
+	return names
+}
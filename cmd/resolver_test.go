@@ -0,0 +1,76 @@
+package view
+
+import (
+	"context"
+	"slices"
+	"testing"
+)
+
+func TestParseQuery(t *testing.T) {
+	tests := []struct {
+		query     string
+		resource  string
+		name      string
+		namespace string
+		err       bool
+	}{
+		{query: "xr/example", resource: "xr/example"},
+		{query: "xr example", resource: "xr", name: "example"},
+		{query: "  xr.example.io/example  ", resource: "xr.example.io/example"},
+		{query: "xr/example -n team-a", resource: "xr/example", namespace: "team-a"},
+		{query: "-n team-a xr/example", resource: "xr/example", namespace: "team-a"},
+		{query: "xr --namespace team-a example", resource: "xr", name: "example", namespace: "team-a"},
+		{query: "xr/example -n=team-a", resource: "xr/example", namespace: "team-a"},
+		{query: "xr/example --namespace=team-a", resource: "xr/example", namespace: "team-a"},
+		{query: "xr/example -n a -n b", resource: "xr/example", namespace: "b"},
+		{query: "", err: true},
+		{query: "-n team-a", err: true},
+		{query: "xr/example -n", err: true},
+		{query: "xr/example -A", err: true},
+		{query: "xr a b", err: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.query, func(t *testing.T) {
+			resource, name, namespace, err := parseQuery(tt.query)
+			if tt.err {
+				if err == nil {
+					t.Errorf("expected an error, got %s %s -n %s", resource, name, namespace)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if resource != tt.resource || name != tt.name || namespace != tt.namespace {
+				t.Errorf("expected %q %q -n %q, got %q %q -n %q",
+					tt.resource, tt.name, tt.namespace, resource, name, namespace)
+			}
+		})
+	}
+}
+
+func TestComplete(t *testing.T) {
+	r := &resolver{mock: true}
+
+	tests := []struct {
+		input string
+		want  []string
+	}{
+		{input: "my", want: []string{"myxr"}},
+		{input: "myxr/ex", want: []string{"myxr/example"}},
+		{input: "-n default myxr/ex", want: []string{"-n default myxr/example"}},
+		{input: "myxr ex", want: []string{"myxr example"}},
+		{input: "myxr/example -n de", want: []string{"myxr/example -n default"}},
+		{input: "myxr/example -", want: nil},
+		{input: "myxr/example ex", want: nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			if got := r.complete(context.Background(), tt.input); !slices.Equal(got, tt.want) {
+				t.Errorf("expected %q, got %q", tt.want, got)
+			}
+		})
+	}
+}
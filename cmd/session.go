@@ -0,0 +1,78 @@
+package view
+
+import (
+	"context"
+	"fmt"
+	"sync"
+
+	tea "charm.land/bubbletea/v2"
+	"github.com/nkzk/xrefs/internal/k8s"
+	"github.com/nkzk/xrefs/internal/models"
+	"github.com/nkzk/xrefs/internal/ui"
+)
+
+// session runs the producer of the root shown in the tui, and restarts it
+// when the tui switches to another root.
+type session struct {
+	cmd        *Cmd
+	ctx        context.Context
+	kClient    k8s.Client
+	watcher    k8s.ResourceWatcher
+	prog       sender
+	expansions chan expandRequest
+
+	mu     sync.Mutex
+	cancel context.CancelFunc
+}
+
+// sender sends the messages of a producer to the tui.
+type sender interface {
+	Send(msg tea.Msg)
+}
+
+// tabSender tags the messages of the producer of a tab, so they reach the
+// tree of the tab rather than the one shown.
+type tabSender struct {
+	prog *tea.Program
+	tab  int
+}
+
+func (s tabSender) Send(msg tea.Msg) {
+	s.prog.Send(ui.TabMsg{Tab: s.tab, Msg: msg})
+}
+
+// watch stops the producer of the previous root and starts one for root.
+func (s *session) watch(root *models.Resource) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.cancel != nil {
+		s.cancel()
+	}
+
+	ctx, cancel := context.WithCancel(s.ctx)
+	s.cancel = cancel
+
+	w, err := s.watcher.WatchResource(ctx, root)
+	if err != nil {
+		cancel()
+		return fmt.Errorf("cannot start watch: %v", err)
+	}
+
+	go func() {
+		defer w.Stop()
+		s.cmd.watchProducer(ctx, s.kClient, root, s.prog, w, s.expansions)
+	}()
+
+	return nil
+}
+
+// stop stops the current producer.
+func (s *session) stop() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.cancel != nil {
+		s.cancel()
+	}
+}
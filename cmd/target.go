@@ -0,0 +1,88 @@
+package view
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/nkzk/xrefs/internal/k8s"
+	"github.com/nkzk/xrefs/internal/models"
+)
+
+// Target holds the flags selecting the root resource and the cluster it is
+// read from. It is embedded by the commands working on a resource tree.
+type Target struct {
+	Resource string   `required:"" name:"resource" arg:"" help:"The resource to view refs of, in the format 'TYPE[.VERSION][.GROUP][/NAME]'. required" xor:"resource,development"`
+	Names    []string `name:"name" arg:"" optional:"" help:"resource names, or more resources in the format 'TYPE[.VERSION][.GROUP]/NAME' when the first has a name. optional" group:"resource"`
+
+	Cluster `embed:""`
+}
+
+// Cluster holds the flags selecting the cluster and namespace resources are
+// read from.
+type Cluster struct {
+	Namespace string `default:"" name:"namespace" help:"resource namespace" group:"resource" short:"n"`
+
+	KubeConfig string `default:"" help:"kubernetes kubeconfig location" name:"kube-config"`
+	Context    string `default:"" help:"kubernetes context" name:"context" short:"c"`
+
+	CacheOnDisk bool `help:"enable kubernetes discovery client caching to file instead of memory"`
+
+	Mock bool `default:"false" help:"mock mode for development" group:"development" xor:"resource,development"`
+}
+
+// resolve sets up the clients and fetches the root resource of the target.
+func (t *Target) resolve(ctx context.Context) (k8s.Client, k8s.ResourceWatcher, *models.Resource, error) {
+	r, err := t.newResolver()
+	if err != nil {
+		return nil, nil, nil, err
+	}
+
+	targets, err := t.targets()
+	if err != nil {
+		return nil, nil, nil, err
+	}
+	if len(targets) > 1 {
+		return nil, nil, nil, errors.New("a single resource is required")
+	}
+
+	root, err := r.open(ctx, targets[0].resource, targets[0].name, t.Namespace)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+
+	return r.kClient, r.watcher, root, nil
+}
+
+// target is a root given on the command line.
+type target struct {
+	resource string
+	name     string
+}
+
+// targets splits the arguments into roots like kubectl does, either
+// `TYPE NAME...` or `TYPE/NAME...`.
+func (t *Target) targets() ([]target, error) {
+	if len(t.Names) == 0 {
+		return []target{{resource: t.Resource}}, nil
+	}
+
+	if !strings.Contains(t.Resource, "/") {
+		targets := make([]target, 0, len(t.Names))
+		for _, name := range t.Names {
+			targets = append(targets, target{resource: t.Resource, name: name})
+		}
+		return targets, nil
+	}
+
+	targets := []target{{resource: t.Resource}}
+	for _, name := range t.Names {
+		if !strings.Contains(name, "/") {
+			return nil, fmt.Errorf("%q has no type, use TYPE[.VERSION][.GROUP]/NAME for every resource when the first has one", name)
+		}
+		targets = append(targets, target{resource: name})
+	}
+
+	return targets, nil
+}
@@ -2,34 +2,40 @@ package view
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"strings"
 	"time"
 
 	tea "charm.land/bubbletea/v2"
 	"github.com/alecthomas/kong"
+	"github.com/nkzk/xrefs/internal/config"
 	"github.com/nkzk/xrefs/internal/k8s"
 	"github.com/nkzk/xrefs/internal/models"
 	"github.com/nkzk/xrefs/internal/ui"
 	corev1 "k8s.io/api/core/v1"
 	apierrors "k8s.io/apimachinery/pkg/api/errors"
 	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
-	"k8s.io/apimachinery/pkg/runtime"
-	"k8s.io/apimachinery/pkg/runtime/schema"
 	"k8s.io/apimachinery/pkg/watch"
 )
 
 type Cmd struct {
-	Resource  string `required:"" name:"resource" arg:"" help:"The resource to view refs of, in the format 'TYPE[.VERSION][.GROUP][/NAME]'. required" xor:"resource,development"`
-	Name      string `default:"" name:"name" arg:"" help:"resource name. optional" group:"resource"`
-	Namespace string `default:"" name:"namespace" help:"resource namespace" group:"resource" short:"n"`
+	Target `embed:""`
 
-	KubeConfig string `default:"" help:"kubernetes kubeconfig location" name:"kube-config"`
-	Context    string `default:"" help:"kubernetes context" name:"context" short:"c"`
+	FollowDeletion bool          `help:"keep running when the resource is deleted and show the deletion progress until all resources are gone" name:"follow-deletion"`
+	StuckThreshold time.Duration `default:"5m" help:"deletions pending for longer than this are reported as stuck" name:"stuck-threshold"`
+	ExpandDepth    int           `default:"1" help:"number of levels below the resource to expand initially, use --expand-depth=-1 to expand the whole tree" name:"expand-depth"`
 
-	CacheOnDisk bool `help:"enable kubernetes discovery client caching to file instead of memory"`
+	Selector      string `default:"" help:"show the objects of the resource type matching a label selector under one root, like team=payments" name:"selector" short:"l"`
+	AllNamespaces bool   `help:"show the objects of the resource type in all namespaces under one root" name:"all-namespaces" short:"A"`
 
-	Mock bool `default:"false" help:"mock mode for development" group:"development" xor:"resource,development"`
+	Display `embed:""`
+}
+
+// expandRequest asks the producer to load the subtree of a node.
+type expandRequest struct {
+	id    string
+	depth int
 }
 
 func (c *Cmd) Help() string {
@@ -40,123 +46,203 @@ func (c *Cmd) Help() string {
 	  xrefs view <kind>.<version>.<api-group>/<name>
 
 	  xrefs view my-xr.alphav1.example.io/name
+
+	Several resources are shown in tabs:
+	  xrefs view claim.example.io/db kustomization/db applications.argoproj.io/db
+
+	A set of objects is shown under one root:
+	  xrefs view claim -l team=payments -A
 	`
 }
 
 func (c *Cmd) Run(k *kong.Context) error {
 	ctx := context.Background()
 
-	if c.Mock {
-		return c.runMock(ctx, k)
+	targets, err := c.targets()
+	if err != nil {
+		return err
 	}
 
-	return c.runKubernetes(ctx, k)
-}
-
-func (c *Cmd) runMock(ctx context.Context, k *kong.Context) error {
-	kClient := k8s.NewMockClient()
-	watcher := k8s.NewMockResourceWatcher()
-
-	rootRef := &corev1.ObjectReference{
-		APIVersion: "example.io/v1alpha1",
-		Kind:       "MyXR",
-		Name:       "example",
-		Namespace:  "default",
+	settings, err := c.settings()
+	if err != nil {
+		return err
 	}
 
-	if c.Resource == "kustomization" {
-		rootRef = &corev1.ObjectReference{
-			APIVersion: "kustomize.toolkit.fluxcd.io/v1",
-			Kind:       "Kustomization",
-			Name:       "example",
-			Namespace:  "default",
-		}
+	theme, err := c.theme(settings)
+	if err != nil {
+		return err
 	}
 
-	root, err := kClient.GetUnstructured(ctx, rootRef)
+	r, err := c.newResolver()
 	if err != nil {
 		return err
 	}
 
-	rootResource := models.NewResource(
-		nil,
-		root,
-		rootRef,
-	)
-	rootResource.Expanded = true
+	if c.Selector != "" || c.AllNamespaces {
+		if len(targets) > 1 || targets[0].name != "" || strings.Contains(targets[0].resource, "/") {
+			return errors.New("--selector and --all-namespaces take a resource type without names")
+		}
 
-	return c.watchResourceTree(ctx, k, kClient, watcher, rootResource)
-}
+		root, err := r.selection(targets[0].resource, c.Selector, c.AllNamespaces)
+		if err != nil {
+			return err
+		}
 
-func (c *Cmd) runKubernetes(ctx context.Context, k *kong.Context) error {
-	clientconfig, client, rmapper, err := k8s.SetupKubeClient(c.KubeConfig, c.Context, c.CacheOnDisk)
-	if err != nil {
+		if c.ExpandDepth >= 0 {
+			c.ExpandDepth++ // the synthetic root is not a level of the trees
+		}
+
+		_, err = c.watchResourceTrees(ctx, k, r, []*models.Resource{root}, settings, theme)
 		return err
 	}
 
-	resource, name, err := k8s.ParseResourceName(c.Resource, c.Name)
-	if err != nil {
-		return err
+	roots := make([]*models.Resource, 0, len(targets))
+	for _, t := range targets {
+		root, err := r.open(ctx, t.resource, t.name, c.Namespace)
+		if errors.Is(err, errNoName) && len(targets) == 1 {
+			root, err = c.pick(ctx, k, r, settings, theme)
+		}
+		if err != nil {
+			return err
+		}
+		if root == nil {
+			return nil // the picker was quit
+		}
+		roots = append(roots, root)
 	}
 
-	resourceMapping, err := k8s.MappingFor(rmapper, resource)
+	_, err = c.watchResourceTrees(ctx, k, r, roots, settings, theme)
+	return err
+}
+
+// pick lets the root be chosen from the objects of the target type.
+func (c *Cmd) pick(
+	ctx context.Context,
+	k *kong.Context,
+	r *resolver,
+	settings config.Config,
+	theme config.Theme,
+) (*models.Resource, error) {
+	resource, _, err := k8s.ParseResourceName(c.Resource, "")
 	if err != nil {
-		return err
+		return nil, err
 	}
 
-	resourceObjectRef, err := k8s.ResourceObjectRefFromMapping(
-		resourceMapping,
-		clientconfig,
-		name,
-		c.Namespace,
-	)
+	candidates, err := r.candidates(ctx, resource, c.Namespace)
 	if err != nil {
-		return err
+		return nil, err
+	}
+
+	switch len(candidates) {
+	case 0:
+		return nil, fmt.Errorf("no %s found", resource)
+	case 1:
+		candidates[0].Expanded = true
+		return candidates[0], nil
 	}
 
-	kClient := k8s.NewK8sClient(client)
-	watcher := k8s.NewKubernetesResourceWatcher(client)
+	picker := ui.NewPicker("Select "+resource, candidates, ui.Config{
+		Settings: settings,
+		Theme:    &theme,
+	})
 
-	root, err := kClient.GetUnstructured(ctx, resourceObjectRef)
+	m, err := tea.NewProgram(picker, tea.WithOutput(k.Stdout)).Run()
 	if err != nil {
-		return err
+		return nil, err
 	}
 
-	rootResource := models.NewResource(
-		nil,
-		root,
-		resourceObjectRef,
-	)
-	rootResource.Expanded = true
+	root := m.(ui.Picker).Selected()
+	if root != nil {
+		root.Expanded = true
+	}
 
-	return c.watchResourceTree(ctx, k, kClient, watcher, rootResource)
+	return root, nil
 }
 
-func (c *Cmd) watchResourceTree(
+// watchResourceTrees runs the tui on roots, each in a tab of its own when
+// there are several, reporting whether it was left with the back key from
+// the root.
+func (c *Cmd) watchResourceTrees(
 	ctx context.Context,
 	k *kong.Context,
-	kClient k8s.Client,
-	watcher k8s.ResourceWatcher,
-	root *models.Resource,
-) error {
-	w, err := watcher.WatchResource(ctx, root)
-	if err != nil {
-		return fmt.Errorf("cannot start watch: %v", err)
+	r *resolver,
+	roots []*models.Resource,
+	settings config.Config,
+	theme config.Theme,
+) (back bool, err error) {
+	sessions := make([]*session, 0, len(roots))
+	trees := make([]*ui.Model, 0, len(roots))
+	for _, root := range roots {
+		s := &session{
+			cmd:        c,
+			ctx:        ctx,
+			kClient:    r.kClient,
+			watcher:    r.watcher,
+			expansions: make(chan expandRequest, 16),
+		}
+		sessions = append(sessions, s)
+
+		trees = append(trees, ui.NewModel(root, ui.Config{
+			Client:         r.kClient,
+			StuckThreshold: c.StuckThreshold,
+			Settings:       settings,
+			Theme:          &theme,
+			Expand: func(id string, depth int) {
+				select {
+				case s.expansions <- expandRequest{id: id, depth: depth}:
+				default: // the producer is busy, the next refresh picks it up
+				}
+			},
+			Watch: s.watch,
+			Open: func(query string) (*models.Resource, error) {
+				ctx, cancel := context.WithTimeout(ctx, 30*time.Second)
+				defer cancel()
+				return r.query(ctx, query)
+			},
+			Complete: func(input string) []string {
+				ctx, cancel := context.WithTimeout(ctx, 5*time.Second)
+				defer cancel()
+				return r.complete(ctx, input)
+			},
+		}))
 	}
-	defer w.Stop()
 
-	prog := tea.NewProgram(ui.NewModel(root), tea.WithOutput(k.Stdout))
+	var model tea.Model = trees[0]
+	if len(trees) > 1 {
+		model = ui.NewTabs(trees)
+	}
+	prog := tea.NewProgram(model, tea.WithOutput(k.Stdout))
 
-	go c.watchProducer(ctx, kClient, root, prog, w)
+	for i, s := range sessions {
+		s.prog = prog
+		if len(sessions) > 1 {
+			s.prog = tabSender{prog: prog, tab: i}
+		}
 
-	_, err = prog.Run()
-	return err
+		if err := s.watch(roots[i]); err != nil {
+			return false, err
+		}
+		defer s.stop()
+	}
+
+	m, err := prog.Run()
+	if m, ok := m.(ui.Model); ok {
+		back = m.Back()
+	}
+	return back, err
 }
 
 // runs the watchProducer loop for a resource and sends updates to bubbletea tui
-func (c *Cmd) watchProducer(ctx context.Context, kClient k8s.Client, root *models.Resource, prog *tea.Program, w watch.Interface) {
-	if err := update(ctx, root, kClient, prog); err != nil {
-		c.handleProducerError(prog, err)
+func (c *Cmd) watchProducer(
+	ctx context.Context,
+	kClient k8s.Client,
+	root *models.Resource,
+	prog sender,
+	w watch.Interface,
+	expansions <-chan expandRequest,
+) {
+	if err := k8s.LoadTree(ctx, root, kClient, c.ExpandDepth); err != nil {
+		c.handleProducerError(ctx, prog, err)
 
 		return
 	}
@@ -184,199 +270,90 @@ func (c *Cmd) watchProducer(ctx context.Context, kClient k8s.Client, root *model
 		select {
 		case evt, ok := <-w.ResultChan():
 			if !ok {
-				prog.Send(ui.QuitMsg{})
+				if ctx.Err() == nil {
+					prog.Send(ui.QuitMsg{})
+				}
 				return
 			}
 			if evt.Type == watch.Deleted {
-				prog.Send(ui.RootDeletedMsg{})
+				prog.Send(ui.RootDeletedMsg{Follow: c.FollowDeletion})
+				if c.FollowDeletion {
+					c.followDeletion(ctx, kClient, root, prog)
+				}
 				return
 			}
-			if err := update(ctx, root, kClient, prog); err != nil {
-				c.handleProducerError(prog, err)
+			if err := k8s.Refresh(ctx, root, kClient); err != nil {
+				c.handleProducerError(ctx, prog, err)
 				return
 			}
 			prog.Send(ui.UpdateResourceMsg{
 				Resource: root,
 			})
 
-		case <-tickerC:
-			if err := update(ctx, root, kClient, prog); err != nil {
-				c.handleProducerError(prog, err)
+		case req := <-expansions:
+			node := root.Find(req.id)
+			if node == nil {
+				continue
+			}
+
+			if err := k8s.LoadTree(ctx, node, kClient, req.depth); err != nil {
+				c.handleProducerError(ctx, prog, err)
 				return
 			}
 
 			prog.Send(ui.UpdateResourceMsg{
 				Resource: root,
 			})
-		case <-ctx.Done():
-			prog.Send(ui.QuitMsg{})
-			return
-		}
-	}
-}
 
-// updateAndSend updates a Resource and its children and send an UpdateResourceMsg to tea.Program
-func update(ctx context.Context, r *models.Resource, kClient k8s.Client, prog *tea.Program) error {
-	current, err := kClient.GetUnstructured(ctx, r.Ref)
-	if err != nil {
-		if apierrors.IsNotFound(err) {
-			r.NotFound = true
-			return nil
-		}
-
-		return err
-	}
-
-	r.NotFound = false
-
-	// Update unstructured
-	r.Unstructured = current
-
-	// Update Resource conditions
-	freshConditions := []models.Condition{}
-	conditions, ok, err := unstructured.NestedSlice(current.Object, "status", "conditions")
-	if ok && err == nil {
-		for _, c := range conditions {
-			var condition models.Condition
-			if err := runtime.DefaultUnstructuredConverter.FromUnstructured(
-				c.(map[string]any),
-				&condition,
-			); err == nil {
-				freshConditions = append(freshConditions, condition)
+		case <-tickerC:
+			if err := k8s.Refresh(ctx, root, kClient); err != nil {
+				c.handleProducerError(ctx, prog, err)
+				return
 			}
-		}
-	}
-
-	r.Conditions = freshConditions
-
-	loadResourceChildren(r)
-
-	if !r.Expanded {
-		return nil
-	}
 
-	r.ChildrenLoaded = true
-
-	// Update children
-	for i := range r.Children {
-		if err := update(ctx, &r.Children[i], kClient, prog); err != nil {
-			return err
+			prog.Send(ui.UpdateResourceMsg{
+				Resource: root,
+			})
+		case <-ctx.Done():
+			return
 		}
 	}
-
-	return nil
 }
 
-// loads resource-refs of a root resource to the Children array.
-func loadResourceChildren(root *models.Resource) {
-	existingChildren := make(map[string]*models.Resource)
-	for i := range root.Children {
-		c := &root.Children[i]
-		if c.Ref != nil {
-			key := fmt.Sprintf("%s/%s/%s", c.Ref.APIVersion, c.Ref.Kind, c.Ref.Name)
-			existingChildren[key] = c
-		}
-	}
-
-	var newChildren []models.Resource
+// followDeletion polls the tree of a deleted root until all resources in it
+// are gone, so the deletion progress can be followed in the tui. The whole
+// tree is polled, also below collapsed nodes, so no resource is missed.
+func (c *Cmd) followDeletion(ctx context.Context, kClient k8s.Client, root *models.Resource, prog sender) {
+	ticker := time.NewTicker(2 * time.Second)
+	defer ticker.Stop()
 
-	switch root.Unstructured.GroupVersionKind() {
-	case schema.GroupVersionKind{
-		Group:   "kustomize.toolkit.fluxcd.io",
-		Version: "v1",
-		Kind:    "Kustomization"}:
-		entries, ok, err := unstructured.NestedSlice(root.Unstructured.Object, "status", "inventory", "entries")
-		if err != nil || !ok {
-			root.Children = nil
+	for {
+		if err := k8s.RefreshAll(ctx, root, kClient); err != nil {
+			c.handleProducerError(ctx, prog, err)
 			return
 		}
 
-		for _, e := range entries {
-			m, ok := e.(map[string]any)
-			if !ok {
-				continue
-			}
-
-			// id format: <namespace>_<name>_<group>_<kind>
-			// "v" for version
-			id, _ := m["id"].(string)
-			version, _ := m["v"].(string)
-
-			parts := strings.SplitN(id, "_", 4)
-			if len(parts) < 4 {
-				continue
-			}
-
-			ns, name, group, kind := parts[0], parts[1], parts[2], parts[3]
-
-			apiVersion := version
-			if group != "" {
-				apiVersion = group + "/" + version
-			}
-
-			ref := &corev1.ObjectReference{
-				APIVersion: apiVersion,
-				Kind:       kind,
-				Name:       name,
-				Namespace:  ns,
-			}
-
-			newChildren = append(newChildren, *models.NewResource(nil, nil, ref))
-		}
-	default:
-		// assume crossplane XR
+		prog.Send(ui.UpdateResourceMsg{
+			Resource: root,
+		})
 
-		resourceRefs, ok, err := unstructured.NestedSlice(root.Unstructured.Object, "spec", "crossplane", "resourceRefs")
-		if err != nil || !ok {
-			root.Children = nil
+		if root.Remaining() == 0 {
+			prog.Send(ui.DeletionCompleteMsg{})
 			return
 		}
 
-		parentNS := root.Unstructured.GetNamespace()
-
-		for _, r := range resourceRefs {
-			ref := corev1.ObjectReference{}
-
-			m, ok := r.(map[string]any)
-			if !ok {
-				continue
-			}
-
-			if err := runtime.DefaultUnstructuredConverter.FromUnstructured(m, &ref); err != nil {
-				continue
-			}
-
-			if ref.Namespace == "" && parentNS != "" {
-				ref.Namespace = parentNS
-			}
-
-			newChildren = append(newChildren, *models.NewResource(nil, nil, &ref))
-		}
-	}
-
-	// Merge: preserve Expanded/ChildrenLoaded/Children state from existing children
-	for i := range newChildren {
-		ref := newChildren[i].Ref
-		if ref != nil {
-			key := fmt.Sprintf("%s/%s/%s", ref.APIVersion, ref.Kind, ref.Name)
-			if existing, ok := existingChildren[key]; ok {
-				newChildren[i].Expanded = existing.Expanded
-				newChildren[i].ChildrenLoaded = existing.ChildrenLoaded
-				newChildren[i].Children = existing.Children
-				newChildren[i].Unstructured = existing.Unstructured
-				newChildren[i].Conditions = existing.Conditions
-				newChildren[i].Error = existing.Error
-				newChildren[i].NotFound = existing.NotFound
-			}
+		select {
+		case <-ticker.C:
+		case <-ctx.Done():
+			return
 		}
 	}
-
-	root.Children = newChildren
 }
 
-// handleProducerError handles errors from the watch producer.
-func (c *Cmd) handleProducerError(prog *tea.Program, err error) {
-	if apierrors.IsNotFound(err) {
+// handleProducerError handles errors from the watch producer. Errors of a
+// producer that was stopped, because the tui switched roots, are dropped.
+func (c *Cmd) handleProducerError(ctx context.Context, prog sender, err error) {
+	if apierrors.IsNotFound(err) || ctx.Err() != nil {
 		return
 	}
 
@@ -385,7 +362,7 @@ func (c *Cmd) handleProducerError(prog *tea.Program, err error) {
 
 // buildUsageTree builds an alternative tree from Usage objects already present
 // in root.Children, re-parenting resources based on usage relationships.
-func (c *Cmd) buildUsageTree(ctx context.Context, kClient k8s.Client, root *models.Resource, prog *tea.Program) {
+func (c *Cmd) buildUsageTree(ctx context.Context, kClient k8s.Client, root *models.Resource, prog sender) {
 	// Collect Usage objects from existing children
 	var usages []unstructured.Unstructured
 	for _, child := range root.Children {
@@ -409,59 +386,20 @@ func (c *Cmd) buildUsageTree(ctx context.Context, kClient k8s.Client, root *mode
 	edges := []usageEdge{}
 
 	for _, u := range usages {
-		ofData, _, _ := unstructured.NestedMap(u.Object, "spec", "of")
-		byData, _, _ := unstructured.NestedMap(u.Object, "spec", "by")
-
-		if ofData == nil || byData == nil {
-			continue
-		}
-
-		ofAPIVersion, _ := ofData["apiVersion"].(string)
-		ofKind, _ := ofData["kind"].(string)
-		ofName, _ := ofData["resourceRef"].(map[string]any)
-		if ofName == nil {
-			// try direct name field
-			n, _ := ofData["name"].(string)
-			if n == "" {
-				continue
-			}
-			ofName = map[string]any{"name": n}
-		}
-		ofResourceName, _ := ofName["name"].(string)
-
-		byAPIVersion, _ := byData["apiVersion"].(string)
-		byKind, _ := byData["kind"].(string)
-		byResRef, _ := byData["resourceRef"].(map[string]any)
-		if byResRef == nil {
-			n, _ := byData["name"].(string)
-			if n == "" {
-				continue
-			}
-			byResRef = map[string]any{"name": n}
-		}
-		byResourceName, _ := byResRef["name"].(string)
-
-		if ofKind == "" || ofResourceName == "" || byKind == "" || byResourceName == "" {
+		usage, ok := k8s.ParseUsage(&u)
+		if !ok || usage.By.Kind == "" {
 			continue
 		}
 
-		if ofAPIVersion == "" {
-			ofAPIVersion = "v1"
-		}
-		if byAPIVersion == "" {
-			byAPIVersion = "v1"
+		ofRef := usage.Of
+		if ofRef.APIVersion == "" {
+			ofRef.APIVersion = "v1"
 		}
-
-		byKey := fmt.Sprintf("%s/%s", byKind, byResourceName)
+		ofRef.Namespace = root.Unstructured.GetNamespace()
 
 		edges = append(edges, usageEdge{
-			byKey: byKey,
-			ofRef: corev1.ObjectReference{
-				APIVersion: ofAPIVersion,
-				Kind:       ofKind,
-				Name:       ofResourceName,
-				Namespace:  root.Unstructured.GetNamespace(),
-			},
+			byKey: fmt.Sprintf("%s/%s", usage.By.Kind, usage.By.Name),
+			ofRef: ofRef,
 		})
 	}
 
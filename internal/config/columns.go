@@ -0,0 +1,114 @@
+package config
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+
+	"k8s.io/client-go/util/jsonpath"
+)
+
+// ColumnType is what a column shows.
+type ColumnType string
+
+const (
+	ColumnNamespace ColumnType = "NAMESPACE"
+	ColumnReady     ColumnType = "READY"
+	ColumnSynced    ColumnType = "SYNCED"
+	ColumnHealth    ColumnType = "HEALTH"
+	ColumnReason    ColumnType = "REASON"
+	ColumnAge       ColumnType = "AGE"
+
+	// ColumnCondition shows the status of an arbitrary condition type.
+	ColumnCondition ColumnType = "cond"
+	// ColumnJSONPath shows the result of a JSONPath expression on the object.
+	ColumnJSONPath ColumnType = "jsonpath"
+)
+
+// DefaultColumns are the columns shown when none are configured.
+var DefaultColumns = []string{"NAMESPACE", "READY", "SYNCED", "HEALTH", "REASON"}
+
+// Column is a parsed column spec.
+type Column struct {
+	Header string
+	Type   ColumnType
+
+	// Condition is the condition type shown by a ColumnCondition.
+	Condition string
+
+	path *jsonpath.JSONPath
+}
+
+// ParseColumn parses a column spec, which is one of
+//
+//	NAMESPACE, READY, SYNCED, HEALTH, REASON or AGE
+//	cond:<type>                     the status of a condition, e.g. cond:Healthy
+//	<HEADER>:<jsonpath>             e.g. EXTERNAL:.metadata.annotations.crossplane\.io/external-name
+//
+// JSONPath expressions may be given with or without the surrounding braces.
+func ParseColumn(spec string) (Column, error) {
+	spec = strings.TrimSpace(spec)
+
+	switch t := ColumnType(strings.ToUpper(spec)); t {
+	case ColumnNamespace, ColumnReady, ColumnSynced, ColumnHealth, ColumnReason, ColumnAge:
+		return Column{Header: string(t), Type: t}, nil
+	}
+
+	header, expr, ok := strings.Cut(spec, ":")
+	if !ok || header == "" || expr == "" {
+		return Column{}, fmt.Errorf("invalid column %q, expected a builtin column, cond:<type> or <HEADER>:<jsonpath>", spec)
+	}
+
+	if header == string(ColumnCondition) {
+		return Column{
+			Header:    strings.ToUpper(expr),
+			Type:      ColumnCondition,
+			Condition: expr,
+		}, nil
+	}
+
+	if !strings.HasPrefix(expr, "{") {
+		if !strings.HasPrefix(expr, ".") {
+			expr = "." + expr
+		}
+		expr = "{" + expr + "}"
+	}
+
+	p := jsonpath.New(header).AllowMissingKeys(true)
+	if err := p.Parse(expr); err != nil {
+		return Column{}, fmt.Errorf("invalid column %q: %w", spec, err)
+	}
+
+	return Column{
+		Header: strings.ToUpper(header),
+		Type:   ColumnJSONPath,
+		path:   p,
+	}, nil
+}
+
+// ParseColumns parses a list of column specs.
+func ParseColumns(specs []string) ([]Column, error) {
+	columns := make([]Column, 0, len(specs))
+	for _, s := range specs {
+		c, err := ParseColumn(s)
+		if err != nil {
+			return nil, err
+		}
+		columns = append(columns, c)
+	}
+	return columns, nil
+}
+
+// Lookup evaluates the JSONPath of a ColumnJSONPath column on obj. It
+// returns an empty string when nothing matches.
+func (c Column) Lookup(obj map[string]any) string {
+	if c.path == nil || obj == nil {
+		return ""
+	}
+
+	var b bytes.Buffer
+	if err := c.path.Execute(&b, obj); err != nil {
+		return ""
+	}
+	return b.String()
+}
@@ -0,0 +1,71 @@
+package config
+
+import "testing"
+
+func TestParseColumn(t *testing.T) {
+	obj := map[string]any{
+		"metadata": map[string]any{
+			"name": "db",
+			"annotations": map[string]any{
+				"crossplane.io/external-name": "prod-db",
+			},
+		},
+	}
+
+	tests := []struct {
+		spec   string
+		header string
+		typ    ColumnType
+		value  string
+	}{
+		{spec: "age", header: "AGE", typ: ColumnAge},
+		{spec: "cond:Healthy", header: "HEALTHY", typ: ColumnCondition},
+		{spec: `external:metadata.annotations.crossplane\.io/external-name`, header: "EXTERNAL", typ: ColumnJSONPath, value: "prod-db"},
+		{spec: "NAME:{.metadata.name}", header: "NAME", typ: ColumnJSONPath, value: "db"},
+		{spec: "MISSING:.spec.forProvider.region", header: "MISSING", typ: ColumnJSONPath},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.spec, func(t *testing.T) {
+			c, err := ParseColumn(tt.spec)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if c.Header != tt.header || c.Type != tt.typ {
+				t.Errorf("expected %s %s, got %s %s", tt.header, tt.typ, c.Header, c.Type)
+			}
+			if got := c.Lookup(obj); got != tt.value {
+				t.Errorf("expected value %q, got %q", tt.value, got)
+			}
+		})
+	}
+
+	for _, spec := range []string{"bogus", ":.metadata.name", "X:{.metadata["} {
+		if _, err := ParseColumn(spec); err == nil {
+			t.Errorf("expected %q to be invalid", spec)
+		}
+	}
+}
+
+func TestColumnsFor(t *testing.T) {
+	c := Config{
+		Columns: []string{"READY"},
+		Views: map[string]View{
+			"example.io/v1/XDatabase": {Columns: []string{"AGE", "REASON"}},
+			"Kustomization":           {Columns: []string{"cond:Reconciling"}},
+		},
+	}
+
+	if got := c.ColumnsFor("example.io/v1/", "XDatabase"); len(got) != 1 {
+		t.Errorf("expected the default columns for a different apiVersion, got %d", len(got))
+	}
+	if got := c.ColumnsFor("example.io/v1", "XDatabase"); len(got) != 2 {
+		t.Errorf("expected the view by apiVersion/Kind, got %d columns", len(got))
+	}
+	if got := c.ColumnsFor("kustomize.toolkit.fluxcd.io/v1", "Kustomization"); len(got) != 1 || got[0].Condition != "Reconciling" {
+		t.Errorf("expected the view by Kind, got %+v", got)
+	}
+	if got := (Config{}).ColumnsFor("v1", "ConfigMap"); len(got) != len(DefaultColumns) {
+		t.Errorf("expected the default columns, got %d", len(got))
+	}
+}
@@ -0,0 +1,128 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Config is the user configuration of xrefs, read from
+// $XDG_CONFIG_HOME/xrefs/config.yaml by default.
+type Config struct {
+	// Columns are the columns shown after the tree, see ParseColumn for the
+	// format. Defaults to DefaultColumns.
+	Columns []string `yaml:"columns"`
+
+	// Views are column sets used instead of Columns when the root resource
+	// matches the key, either `apiVersion/Kind` or just `Kind`.
+	Views map[string]View `yaml:"views"`
+
+	// Theme is the name of a builtin theme (dark, light or high-contrast),
+	// of a theme file in the themes directory, the path of a theme file, or
+	// k9s to follow the active k9s skin.
+	Theme string `yaml:"theme"`
+
+	// Keys rebinds actions, like `reconcile: [ctrl+r]`. Press ? in the tui
+	// for the names of the actions.
+	Keys map[string][]string `yaml:"keys"`
+}
+
+// View is a set of columns for a kind of root resource.
+type View struct {
+	Columns []string `yaml:"columns"`
+}
+
+// DefaultPath returns the location of the config file.
+func DefaultPath() (string, error) {
+	dir, err := configDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, "config.yaml"), nil
+}
+
+// configDir returns the config directory of xrefs, under $XDG_CONFIG_HOME or
+// ~/.config on every platform, like the discovery cache under ~/.cache.
+func configDir() (string, error) {
+	if dir := os.Getenv("XDG_CONFIG_HOME"); dir != "" {
+		return filepath.Join(dir, "xrefs"), nil
+	}
+
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(home, ".config", "xrefs"), nil
+}
+
+// Load reads the config file at path, or at DefaultPath when path is empty.
+// A missing file at the default path is not an error.
+func Load(path string) (Config, error) {
+	explicit := path != ""
+	if !explicit {
+		p, err := DefaultPath()
+		if err != nil {
+			return Config{}, nil
+		}
+		path = p
+	}
+
+	b, err := os.ReadFile(path)
+	if err != nil {
+		if errors.Is(err, fs.ErrNotExist) && !explicit {
+			return Config{}, nil
+		}
+		return Config{}, fmt.Errorf("read config: %w", err)
+	}
+
+	var c Config
+	if err := yaml.Unmarshal(b, &c); err != nil {
+		return Config{}, fmt.Errorf("parse %s: %w", path, err)
+	}
+
+	if err := c.Validate(); err != nil {
+		return Config{}, fmt.Errorf("%s: %w", path, err)
+	}
+
+	return c, nil
+}
+
+// Validate checks that the configuration can be used.
+func (c Config) Validate() error {
+	if _, err := ParseColumns(c.Columns); err != nil {
+		return err
+	}
+
+	for key, v := range c.Views {
+		if _, err := ParseColumns(v.Columns); err != nil {
+			return fmt.Errorf("view %s: %w", key, err)
+		}
+	}
+
+	return nil
+}
+
+// ColumnsFor returns the columns for a tree whose root has the given
+// apiVersion and kind. The config is expected to be validated.
+func (c Config) ColumnsFor(apiVersion, kind string) []Column {
+	specs := c.Columns
+	if v, ok := c.Views[apiVersion+"/"+kind]; ok {
+		specs = v.Columns
+	} else if v, ok := c.Views[kind]; ok {
+		specs = v.Columns
+	}
+
+	if len(specs) == 0 {
+		specs = DefaultColumns
+	}
+
+	columns, err := ParseColumns(specs)
+	if err != nil {
+		columns, _ = ParseColumns(DefaultColumns)
+	}
+	return columns
+}
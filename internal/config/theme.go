@@ -0,0 +1,157 @@
+package config
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Theme is the colour palette of the tui. Colours are hex values like
+// "#5fd787" or ANSI colour numbers, empty means the terminal default.
+type Theme struct {
+	// Base is the builtin theme a theme file starts from, dark by default.
+	Base string `yaml:"base,omitempty"`
+
+	Foreground string `yaml:"foreground"` // rows
+	Muted      string `yaml:"muted"`      // headers and footers
+	Selected   string `yaml:"selected"`
+	// SelectedBackground is the background of the selected row, empty for none.
+	SelectedBackground string `yaml:"selectedBackground"`
+	Title              string `yaml:"title"`
+	Success            string `yaml:"success"` // healthy resources and added lines
+	Warning            string `yaml:"warning"` // prompts and unknown status
+	Error              string `yaml:"error"`
+	NotFound           string `yaml:"notFound"`
+	Suspended          string `yaml:"suspended"` // suspended or paused resources
+	Info               string `yaml:"info"`      // search matches and diff hunks
+
+	// Syntax is the chroma style highlighting YAML, empty disables highlighting.
+	Syntax string `yaml:"syntax"`
+}
+
+var (
+	DarkTheme = Theme{
+		Foreground: "#9b9b9b",
+		Muted:      "#6f6f6f",
+		Selected:   "#ffffff",
+		Title:      "#ffffff",
+		Success:    "#5fd787",
+		Warning:    "#ffd75f",
+		Error:      "#ff5f5f",
+		NotFound:   "#ff9898",
+		Suspended:  "#d7af5f",
+		Info:       "#5fafd7",
+		Syntax:     "friendly",
+	}
+
+	LightTheme = Theme{
+		Foreground: "#4e4e4e",
+		Muted:      "#8a8a8a",
+		Selected:   "#000000",
+		Title:      "#000000",
+		Success:    "#008700",
+		Warning:    "#af8700",
+		Error:      "#d70000",
+		NotFound:   "#d75f5f",
+		Suspended:  "#af5f00",
+		Info:       "#005faf",
+		Syntax:     "github",
+	}
+
+	HighContrastTheme = Theme{
+		Foreground: "#ffffff",
+		Muted:      "#d0d0d0",
+		Selected:   "#ffff00",
+		Title:      "#ffffff",
+		Success:    "#00ff00",
+		Warning:    "#ffff00",
+		Error:      "#ff0000",
+		NotFound:   "#ff00ff",
+		Suspended:  "#ff8700",
+		Info:       "#00ffff",
+		Syntax:     "monokai",
+	}
+
+	// NoColorTheme is used when NO_COLOR is set, see https://no-color.org.
+	NoColorTheme = Theme{}
+)
+
+// builtinThemes are the themes selectable by name.
+var builtinThemes = map[string]Theme{
+	"dark":          DarkTheme,
+	"light":         LightTheme,
+	"high-contrast": HighContrastTheme,
+}
+
+// LoadTheme returns the theme with the given name: a builtin theme, the name
+// of a file in the themes directory next to the config file, or the path of a
+// theme file. NO_COLOR takes precedence over any theme.
+func LoadTheme(name string) (Theme, error) {
+	if os.Getenv("NO_COLOR") != "" {
+		return NoColorTheme, nil
+	}
+
+	if name == "" {
+		return DarkTheme, nil
+	}
+
+	if t, ok := builtinThemes[name]; ok {
+		return t, nil
+	}
+
+	path := name
+	if !strings.ContainsRune(name, filepath.Separator) && filepath.Ext(name) == "" {
+		dir, err := configDir()
+		if err != nil {
+			return Theme{}, fmt.Errorf("unknown theme %q", name)
+		}
+		path = filepath.Join(dir, "themes", name+".yaml")
+	}
+
+	b, err := os.ReadFile(path)
+	if err != nil {
+		return Theme{}, fmt.Errorf("theme %q: %w", name, err)
+	}
+
+	var t Theme
+	if err := yaml.Unmarshal(b, &t); err != nil {
+		return Theme{}, fmt.Errorf("parse theme %s: %w", path, err)
+	}
+
+	base, ok := builtinThemes[t.Base]
+	if t.Base == "" {
+		base, ok = DarkTheme, true
+	}
+	if !ok {
+		return Theme{}, fmt.Errorf("theme %s: unknown base theme %q", path, t.Base)
+	}
+
+	return base.Merge(t), nil
+}
+
+// Merge returns t with the colours set in override replacing its own.
+func (t Theme) Merge(override Theme) Theme {
+	set := func(dst *string, src string) {
+		if src != "" {
+			*dst = src
+		}
+	}
+
+	set(&t.Foreground, override.Foreground)
+	set(&t.Muted, override.Muted)
+	set(&t.Selected, override.Selected)
+	set(&t.SelectedBackground, override.SelectedBackground)
+	set(&t.Title, override.Title)
+	set(&t.Success, override.Success)
+	set(&t.Warning, override.Warning)
+	set(&t.Error, override.Error)
+	set(&t.NotFound, override.NotFound)
+	set(&t.Suspended, override.Suspended)
+	set(&t.Info, override.Info)
+	set(&t.Syntax, override.Syntax)
+
+	return t
+}
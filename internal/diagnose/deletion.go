@@ -0,0 +1,225 @@
+package diagnose
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/nkzk/xrefs/internal/k8s"
+	"github.com/nkzk/xrefs/internal/models"
+	v1 "k8s.io/api/core/v1"
+)
+
+// DeletionPlan describes what happens when a resource is deleted.
+type DeletionPlan struct {
+	Target *models.Resource
+
+	// Descendants are the loaded resources below Target, which are removed
+	// by garbage collection or pruning once Target is gone.
+	Descendants []*models.Resource
+
+	// Kept are the loaded resources below Target that are left in place,
+	// the inventory of Flux Kustomizations that do not prune.
+	Kept []*models.Resource
+
+	// Usages are the Crossplane Usages protecting Target or a descendant.
+	Usages []BlockingUsage
+
+	// Policies are the deletion and management policies of the managed
+	// resources that are deleted.
+	Policies []ManagedPolicy
+
+	depths []int // depth of each descendant below Target
+}
+
+// BlockingUsage is a Crossplane Usage that holds back a deletion.
+type BlockingUsage struct {
+	Usage k8s.Usage
+
+	// Blocks is set when the using resource is not deleted along with the
+	// target, so the deletion hangs until the Usage goes away. Otherwise the
+	// Usage only orders the deletion.
+	Blocks bool
+}
+
+// ManagedPolicy is the deletion behaviour of a managed resource.
+type ManagedPolicy struct {
+	Resource           *models.Resource
+	DeletionPolicy     string
+	ManagementPolicies []string
+}
+
+// PlanDeletion plans the deletion of target. Usages are looked up in the whole
+// tree under root, since the using resource is often a sibling of the target.
+func PlanDeletion(root, target *models.Resource) DeletionPlan {
+	plan := DeletionPlan{Target: target}
+
+	deleted := []*models.Resource{}
+
+	var visit func(r *models.Resource, depth int)
+	visit = func(r *models.Resource, depth int) {
+		deleted = append(deleted, r)
+		if depth > 0 {
+			plan.Descendants = append(plan.Descendants, r)
+			plan.depths = append(plan.depths, depth)
+		}
+
+		if deletionPolicy, managementPolicies, ok := k8s.ManagementPolicies(r.Unstructured); ok {
+			plan.Policies = append(plan.Policies, ManagedPolicy{
+				Resource:           r,
+				DeletionPolicy:     deletionPolicy,
+				ManagementPolicies: managementPolicies,
+			})
+		}
+
+		if k8s.IsFluxKustomization(r.Unstructured) && !k8s.FluxPrunes(r.Unstructured) {
+			for i := range r.Children {
+				r.Children[i].Walk(func(r *models.Resource) {
+					plan.Kept = append(plan.Kept, r)
+				})
+			}
+			return
+		}
+
+		for i := range r.Children {
+			visit(&r.Children[i], depth+1)
+		}
+	}
+	visit(target, 0)
+
+	isDeleted := func(ref *v1.ObjectReference) bool {
+		for _, r := range deleted {
+			if k8s.SameObject(ref, objectRef(r)) {
+				return true
+			}
+		}
+		return false
+	}
+
+	for _, u := range usages(root) {
+		if !isDeleted(&u.Of) {
+			continue
+		}
+
+		plan.Usages = append(plan.Usages, BlockingUsage{
+			Usage:  u,
+			Blocks: u.By.Kind == "" || !isDeleted(&u.By),
+		})
+	}
+
+	return plan
+}
+
+// RequiresTypedConfirmation reports whether the deletion removes more than
+// the target itself.
+func (p DeletionPlan) RequiresTypedConfirmation() bool {
+	return len(p.Descendants) > 0
+}
+
+// String renders the plan as a human readable report.
+func (p DeletionPlan) String() string {
+	var b strings.Builder
+
+	fmt.Fprintf(&b, "Deleting %s\n\n", name(p.Target))
+
+	switch {
+	case len(p.Descendants) == 0 && len(p.Kept) == 0:
+		b.WriteString("No descendants are loaded for this resource.\n")
+	case len(p.Descendants) == 0:
+		b.WriteString("No descendants will be deleted.\n")
+	default:
+		fmt.Fprintf(&b, "%d descendants will be deleted:\n", len(p.Descendants))
+		for i, r := range p.Descendants {
+			fmt.Fprintf(&b, "  %s%s\n", strings.Repeat("  ", p.depths[i]-1), name(r))
+		}
+	}
+
+	if len(p.Kept) > 0 {
+		fmt.Fprintf(&b, "\n%d descendants are kept, their Kustomization does not prune:\n", len(p.Kept))
+		for _, r := range p.Kept {
+			fmt.Fprintf(&b, "  %s\n", name(r))
+		}
+	}
+
+	b.WriteString("\n")
+
+	if len(p.Usages) == 0 {
+		b.WriteString("No Crossplane Usages protect these resources.\n")
+	} else {
+		b.WriteString("Crossplane Usages:\n")
+		for _, u := range p.Usages {
+			fmt.Fprintf(&b, "  Usage/%s: %s\n", u.Usage.Name, describeUsage(u))
+		}
+	}
+
+	b.WriteString("\n")
+
+	if len(p.Policies) > 0 {
+		b.WriteString("Managed resources:\n")
+		for _, mp := range p.Policies {
+			external := "external resource is deleted"
+			if !k8s.DeletesExternalResource(mp.DeletionPolicy, mp.ManagementPolicies) {
+				external = "external resource is kept"
+			}
+			fmt.Fprintf(&b, "  %s: deletionPolicy=%s managementPolicies=[%s] (%s)\n",
+				name(mp.Resource),
+				mp.DeletionPolicy,
+				strings.Join(mp.ManagementPolicies, ", "),
+				external,
+			)
+		}
+	}
+
+	return b.String()
+}
+
+func describeUsage(u BlockingUsage) string {
+	of := u.Usage.Of.Kind + "/" + u.Usage.Of.Name
+
+	switch {
+	case u.Usage.By.Kind == "":
+		reason := u.Usage.Reason
+		if reason == "" {
+			reason = "no reason given"
+		}
+		return fmt.Sprintf("BLOCKS deletion of %s (%s)", of, reason)
+	case u.Blocks:
+		return fmt.Sprintf("BLOCKS deletion of %s while %s/%s exists", of, u.Usage.By.Kind, u.Usage.By.Name)
+	default:
+		return fmt.Sprintf("%s is deleted after %s/%s", of, u.Usage.By.Kind, u.Usage.By.Name)
+	}
+}
+
+// usages returns the Crossplane Usages found in the tree.
+func usages(root *models.Resource) []k8s.Usage {
+	var out []k8s.Usage
+	root.Walk(func(r *models.Resource) {
+		if u, ok := k8s.ParseUsage(r.Unstructured); ok {
+			out = append(out, u)
+		}
+	})
+	return out
+}
+
+// objectRef returns a reference to the object of r, preferring the values
+// of the fetched object over the reference it was found by.
+func objectRef(r *models.Resource) *v1.ObjectReference {
+	if r.Unstructured == nil || r.Unstructured.GetKind() == "" {
+		return r.Ref
+	}
+
+	return &v1.ObjectReference{
+		APIVersion: r.Unstructured.GetAPIVersion(),
+		Kind:       r.Unstructured.GetKind(),
+		Name:       r.Unstructured.GetName(),
+		Namespace:  r.Unstructured.GetNamespace(),
+	}
+}
+
+// name returns kind/name of the resource.
+func name(r *models.Resource) string {
+	ref := objectRef(r)
+	if ref == nil {
+		return "-"
+	}
+	return ref.Kind + "/" + ref.Name
+}
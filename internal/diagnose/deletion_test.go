@@ -0,0 +1,190 @@
+package diagnose
+
+import (
+	"context"
+	"testing"
+
+	"github.com/nkzk/xrefs/internal/k8s"
+	"github.com/nkzk/xrefs/internal/models"
+	v1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+)
+
+func resource(apiVersion, kind, name string, spec map[string]any, children ...models.Resource) models.Resource {
+	u := &unstructured.Unstructured{Object: map[string]any{
+		"apiVersion": apiVersion,
+		"kind":       kind,
+		"metadata": map[string]any{
+			"name":      name,
+			"namespace": "default",
+		},
+	}}
+	if spec != nil {
+		u.Object["spec"] = spec
+	}
+
+	r := models.NewResource(nil, u, &v1.ObjectReference{
+		APIVersion: apiVersion,
+		Kind:       kind,
+		Name:       name,
+		Namespace:  "default",
+	})
+	r.Children = children
+	return *r
+}
+
+func usage(name, ofKind, ofName, byKind, byName string) models.Resource {
+	spec := map[string]any{
+		"of": map[string]any{
+			"apiVersion": "example.io/v1",
+			"kind":       ofKind,
+			"resourceRef": map[string]any{
+				"name": ofName,
+			},
+		},
+	}
+	if byKind != "" {
+		spec["by"] = map[string]any{
+			"apiVersion": "example.io/v1",
+			"kind":       byKind,
+			"resourceRef": map[string]any{
+				"name": byName,
+			},
+		}
+	}
+	return resource("protection.crossplane.io/v1beta1", "Usage", name, spec)
+}
+
+func TestPlanDeletion(t *testing.T) {
+	root := resource("example.io/v1", "XR", "root", nil,
+		resource("example.io/v1", "Database", "db", map[string]any{
+			"forProvider":    map[string]any{},
+			"deletionPolicy": "Orphan",
+		}),
+		resource("example.io/v1", "App", "app", map[string]any{
+			"forProvider": map[string]any{},
+		}),
+		usage("app-uses-db", "Database", "db", "App", "app"),
+		usage("protect-db", "Database", "db", "", ""),
+	)
+
+	t.Run("leaf used by a sibling", func(t *testing.T) {
+		plan := PlanDeletion(&root, &root.Children[0])
+
+		if plan.RequiresTypedConfirmation() {
+			t.Errorf("expected leaf deletion not to require typed confirmation")
+		}
+		if len(plan.Usages) != 2 {
+			t.Fatalf("expected 2 usages, got %d", len(plan.Usages))
+		}
+		for _, u := range plan.Usages {
+			if !u.Blocks {
+				t.Errorf("expected Usage/%s to block the deletion", u.Usage.Name)
+			}
+		}
+		if len(plan.Policies) != 1 || plan.Policies[0].DeletionPolicy != "Orphan" {
+			t.Errorf("expected the Orphan deletion policy of the database, got %+v", plan.Policies)
+		}
+	})
+
+	t.Run("whole tree", func(t *testing.T) {
+		plan := PlanDeletion(&root, &root)
+
+		if !plan.RequiresTypedConfirmation() {
+			t.Errorf("expected subtree deletion to require typed confirmation")
+		}
+		if len(plan.Descendants) != 4 {
+			t.Errorf("expected 4 descendants, got %d", len(plan.Descendants))
+		}
+
+		blocking := map[string]bool{}
+		for _, u := range plan.Usages {
+			blocking[u.Usage.Name] = u.Blocks
+		}
+		if blocking["app-uses-db"] {
+			t.Errorf("expected app-uses-db to only order the deletion, the app is deleted as well")
+		}
+		if !blocking["protect-db"] {
+			t.Errorf("expected protect-db to block the deletion")
+		}
+	})
+	t.Run("kustomization that does not prune", func(t *testing.T) {
+		for _, prune := range []bool{false, true} {
+			ks := resource("kustomize.toolkit.fluxcd.io/v1", "Kustomization", "apps", map[string]any{"prune": prune},
+				resource("v1", "ConfigMap", "config", nil),
+			)
+
+			plan := PlanDeletion(&ks, &ks)
+
+			if prune && (len(plan.Descendants) != 1 || len(plan.Kept) != 0) {
+				t.Errorf("expected a pruning Kustomization to delete its inventory, got %d deleted and %d kept", len(plan.Descendants), len(plan.Kept))
+			}
+			if !prune && (len(plan.Descendants) != 0 || len(plan.Kept) != 1) {
+				t.Errorf("expected a Kustomization without prune to keep its inventory, got %d deleted and %d kept", len(plan.Descendants), len(plan.Kept))
+			}
+		}
+	})
+}
+
+// objectClient serves the objects of resources, which are not loaded trees
+// but the objects of a cluster.
+type objectClient struct {
+	k8s.Client
+	objects []models.Resource
+}
+
+func (c objectClient) GetUnstructured(ctx context.Context, ref *v1.ObjectReference) (*unstructured.Unstructured, error) {
+	for _, o := range c.objects {
+		if k8s.SameObject(o.Ref, ref) {
+			return o.Unstructured.DeepCopy(), nil
+		}
+	}
+	return nil, apierrors.NewNotFound(schema.GroupResource{Resource: ref.Kind}, ref.Name)
+}
+
+func TestPlanDeletionBelowCollapsedNodes(t *testing.T) {
+	// the spec of an XR composing children
+	composes := func(children ...models.Resource) map[string]any {
+		var refs []any
+		for _, c := range children {
+			refs = append(refs, map[string]any{"apiVersion": c.Ref.APIVersion, "kind": c.Ref.Kind, "name": c.Ref.Name})
+		}
+		return map[string]any{"crossplane": map[string]any{"resourceRefs": refs}}
+	}
+
+	db := resource("example.io/v1", "Database", "db", map[string]any{
+		"forProvider":    map[string]any{},
+		"deletionPolicy": "Orphan",
+	})
+	protect := usage("protect-db", "Database", "db", "", "")
+	nested := resource("example.io/v1", "XNested", "nested", composes(db, protect))
+	root := resource("example.io/v1", "XR", "root", composes(nested))
+
+	client := objectClient{objects: []models.Resource{root, nested, db, protect}}
+
+	load := func(depth int) DeletionPlan {
+		root := models.NewResource(nil, nil, &v1.ObjectReference{APIVersion: "example.io/v1", Kind: "XR", Name: "root", Namespace: "default"})
+		if err := k8s.LoadTree(context.Background(), root, client, depth); err != nil {
+			t.Fatalf("%v", err)
+		}
+		return PlanDeletion(root, root)
+	}
+
+	// the tree as shown with the nested XR collapsed
+	if plan := load(1); len(plan.Usages) != 0 || len(plan.Policies) != 0 {
+		t.Fatalf("expected the collapsed nested XR to hide its Usage and policy, got %+v", plan)
+	}
+
+	plan := load(-1)
+	if len(plan.Descendants) != 3 {
+		t.Errorf("expected 3 descendants, got %d", len(plan.Descendants))
+	}
+	if len(plan.Usages) != 1 || !plan.Usages[0].Blocks {
+		t.Errorf("expected the Usage below the nested XR to block the deletion, got %+v", plan.Usages)
+	}
+	if len(plan.Policies) != 1 || plan.Policies[0].DeletionPolicy != "Orphan" {
+		t.Errorf("expected the Orphan deletion policy below the nested XR, got %+v", plan.Policies)
+	}
+}
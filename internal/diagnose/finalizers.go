@@ -0,0 +1,152 @@
+package diagnose
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/nkzk/xrefs/internal/k8s"
+	"github.com/nkzk/xrefs/internal/models"
+	"k8s.io/apimachinery/pkg/util/duration"
+)
+
+// DefaultStuckThreshold is how long a deletion may take before it is reported
+// as stuck.
+const DefaultStuckThreshold = 5 * time.Minute
+
+// StuckDeletion is a resource whose deletion has been pending for longer than
+// the threshold, along with what is likely holding it back.
+type StuckDeletion struct {
+	Resource *models.Resource
+	Since    time.Time
+
+	// Finalizers are the finalizers left on the resource.
+	Finalizers []string
+
+	// Usages are the Crossplane Usages protecting the resource via spec.of.
+	Usages []k8s.Usage
+
+	// Children are the children of the resource that still exist.
+	Children []*models.Resource
+}
+
+// knownFinalizers explains what the controllers owning common finalizers are
+// waiting for before removing them.
+var knownFinalizers = map[string]string{
+	"finalizer.managedresource.crossplane.io": "the provider has not deleted the external resource yet, check the Synced condition and the provider logs",
+	"composite.apiextensions.crossplane.io":   "Crossplane is waiting for the composed resources to be deleted",
+	"finalizer.apiextensions.crossplane.io":   "Crossplane is waiting for the composite resource of the claim to be deleted",
+	"usage.apiextensions.crossplane.io":       "Crossplane is waiting for the used resource to be released",
+	"in-use.crossplane.io":                    "the provider config is still used by managed resources",
+	"finalizers.fluxcd.io":                    "the Flux controller is pruning the resources it applied, check its Ready condition",
+	"kubernetes.io/pvc-protection":            "the volume is still mounted by a pod",
+	"kubernetes.io/pv-protection":             "the volume is still bound to a claim",
+	"foregroundDeletion":                      "the garbage collector is deleting dependents with blockOwnerDeletion set",
+	"orphan":                                  "the garbage collector is orphaning the dependents",
+	"resources-finalizer.argocd.argoproj.io":  "Argo CD is deleting the resources of the application",
+}
+
+// StuckDeletions scans the tree for resources that have been deleting for
+// longer than threshold.
+func StuckDeletions(root *models.Resource, threshold time.Duration, now time.Time) []StuckDeletion {
+	all := usages(root)
+
+	var stuck []StuckDeletion
+	root.Walk(func(r *models.Resource) {
+		if r.NotFound || r.Unstructured == nil || r.Unstructured.GetDeletionTimestamp() == nil {
+			return
+		}
+
+		since := r.Unstructured.GetDeletionTimestamp().Time
+		if now.Sub(since) < threshold {
+			return
+		}
+
+		s := StuckDeletion{
+			Resource:   r,
+			Since:      since,
+			Finalizers: r.Unstructured.GetFinalizers(),
+		}
+
+		ref := objectRef(r)
+		for _, u := range all {
+			if k8s.SameObject(&u.Of, ref) {
+				s.Usages = append(s.Usages, u)
+			}
+		}
+
+		for i := range r.Children {
+			if !r.Children[i].NotFound {
+				s.Children = append(s.Children, &r.Children[i])
+			}
+		}
+
+		stuck = append(stuck, s)
+	})
+
+	return stuck
+}
+
+// Blocker returns the most likely reason the deletion is stuck.
+func (s StuckDeletion) Blocker() string {
+	switch {
+	case len(s.Usages) > 0:
+		u := s.Usages[0]
+		if u.By.Kind != "" {
+			return fmt.Sprintf("protected by Usage/%s while %s/%s exists", u.Name, u.By.Kind, u.By.Name)
+		}
+		return fmt.Sprintf("protected by Usage/%s", u.Name)
+	case len(s.Children) > 0:
+		return fmt.Sprintf("waiting for %d children to be deleted", len(s.Children))
+	case len(s.Finalizers) > 0:
+		if explanation, ok := knownFinalizers[s.Finalizers[0]]; ok {
+			return fmt.Sprintf("finalizer %s: %s", s.Finalizers[0], explanation)
+		}
+		return fmt.Sprintf("finalizer %s has not been removed by its controller", s.Finalizers[0])
+	default:
+		return "no finalizers left, the resource should be gone shortly"
+	}
+}
+
+// StuckReport renders the stuck deletions as a human readable report.
+func StuckReport(stuck []StuckDeletion, threshold time.Duration, now time.Time) string {
+	if len(stuck) == 0 {
+		return fmt.Sprintf("No resources have been deleting for more than %s.\n", threshold)
+	}
+
+	var b strings.Builder
+
+	fmt.Fprintf(&b, "%d resources have been deleting for more than %s:\n", len(stuck), threshold)
+
+	for _, s := range stuck {
+		fmt.Fprintf(&b, "\n%s (deleting for %s)\n", name(s.Resource), duration.HumanDuration(now.Sub(s.Since)))
+		fmt.Fprintf(&b, "  likely blocker: %s\n", s.Blocker())
+
+		if len(s.Finalizers) > 0 {
+			b.WriteString("  finalizers:\n")
+			for _, f := range s.Finalizers {
+				fmt.Fprintf(&b, "    - %s\n", f)
+			}
+		}
+
+		if len(s.Usages) > 0 {
+			b.WriteString("  used by:\n")
+			for _, u := range s.Usages {
+				by := u.Reason
+				if u.By.Kind != "" {
+					by = fmt.Sprintf("%s/%s", u.By.Kind, u.By.Name)
+				}
+				fmt.Fprintf(&b, "    - Usage/%s (%s)\n", u.Name, by)
+			}
+		}
+
+		if len(s.Children) > 0 {
+			b.WriteString("  children still present:\n")
+			for _, c := range s.Children {
+				fmt.Fprintf(&b, "    - %s\n", name(c))
+			}
+		}
+	}
+
+	return b.String()
+}
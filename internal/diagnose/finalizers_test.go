@@ -0,0 +1,43 @@
+package diagnose
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/nkzk/xrefs/internal/models"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func TestStuckDeletions(t *testing.T) {
+	now := time.Date(2026, 1, 1, 12, 0, 0, 0, time.UTC)
+
+	root := resource("example.io/v1", "XR", "root", nil,
+		resource("example.io/v1", "Database", "db", map[string]any{"forProvider": map[string]any{}}),
+		resource("example.io/v1", "App", "app", map[string]any{"forProvider": map[string]any{}}),
+		usage("protect-db", "Database", "db", "", ""),
+	)
+
+	deleting := func(r *models.Resource, ago time.Duration, finalizers ...string) {
+		ts := metav1.NewTime(now.Add(-ago))
+		r.Unstructured.SetDeletionTimestamp(&ts)
+		r.Unstructured.SetFinalizers(finalizers)
+	}
+
+	deleting(&root, 20*time.Minute, "composite.apiextensions.crossplane.io")
+	deleting(&root.Children[0], 10*time.Minute, "finalizer.managedresource.crossplane.io")
+	deleting(&root.Children[1], time.Minute, "finalizer.managedresource.crossplane.io")
+
+	stuck := StuckDeletions(&root, 5*time.Minute, now)
+	if len(stuck) != 2 {
+		t.Fatalf("expected 2 stuck deletions, got %d", len(stuck))
+	}
+
+	if got := stuck[0].Blocker(); !strings.Contains(got, "waiting for 3 children") {
+		t.Errorf("expected the XR to wait for its children, got %q", got)
+	}
+
+	if got := stuck[1].Blocker(); !strings.Contains(got, "Usage/protect-db") {
+		t.Errorf("expected the database to be blocked by its Usage, got %q", got)
+	}
+}
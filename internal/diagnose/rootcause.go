@@ -0,0 +1,138 @@
+package diagnose
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/nkzk/xrefs/internal/models"
+	"k8s.io/apimachinery/pkg/util/duration"
+)
+
+// RootCause is an unhealthy resource whose own children are healthy, which
+// makes it the likely reason its ancestors are not Ready.
+type RootCause struct {
+	// Chain is the path from the resource the analysis started at down to
+	// the failing resource, which is the last element.
+	Chain []*models.Resource
+
+	Severity  models.Severity
+	Condition models.Condition
+
+	// Since is when the failing condition last transitioned, zero if unknown.
+	Since time.Time
+}
+
+// Resource returns the failing resource.
+func (c RootCause) Resource() *models.Resource {
+	return c.Chain[len(c.Chain)-1]
+}
+
+// RootCauses walks the tree below start and returns the deepest unhealthy
+// resources whose children are all healthy. The oldest failures come first,
+// since later failures are often a consequence of them.
+func RootCauses(start *models.Resource) []RootCause {
+	var causes []RootCause
+
+	var visit func(r *models.Resource, chain []*models.Resource) bool
+	visit = func(r *models.Resource, chain []*models.Resource) bool {
+		chain = append(chain[:len(chain):len(chain)], r)
+
+		foundBelow := false
+		for i := range r.Children {
+			if visit(&r.Children[i], chain) {
+				foundBelow = true
+			}
+		}
+
+		severity := r.Severity()
+		if foundBelow || severity < models.SeverityUnhealthy {
+			return foundBelow
+		}
+
+		cause := RootCause{
+			Chain:    chain,
+			Severity: severity,
+		}
+
+		if c, ok := r.FailingCondition(); ok {
+			cause.Condition = c
+			cause.Since, _ = time.Parse(time.RFC3339, c.LastTransitionTime)
+		}
+
+		causes = append(causes, cause)
+		return true
+	}
+
+	visit(start, nil)
+
+	sort.SliceStable(causes, func(i, j int) bool {
+		a, b := causes[i], causes[j]
+		if a.Since.Equal(b.Since) {
+			return a.Condition.Reason < b.Condition.Reason
+		}
+		if a.Since.IsZero() || b.Since.IsZero() {
+			return !a.Since.IsZero()
+		}
+		return a.Since.Before(b.Since)
+	})
+
+	return causes
+}
+
+// String renders the cause as a chain, like
+// `XR/a → Application/b → RoleAssignment/c: ReconcileError: 403 ...`.
+func (c RootCause) String() string {
+	names := make([]string, 0, len(c.Chain))
+	for _, r := range c.Chain {
+		names = append(names, name(r))
+	}
+
+	return strings.Join(names, " → ") + ": " + c.Explanation()
+}
+
+// Explanation describes why the failing resource is unhealthy.
+func (c RootCause) Explanation() string {
+	r := c.Resource()
+
+	switch c.Severity {
+	case models.SeverityErrored:
+		return r.Error.Error()
+	case models.SeverityNotFound:
+		return "resource was not found"
+	}
+
+	parts := []string{}
+	if c.Condition.ConditionType != "" {
+		parts = append(parts, fmt.Sprintf("%s=%s", c.Condition.ConditionType, c.Condition.Status))
+	}
+	if c.Condition.Reason != "" {
+		parts = append(parts, c.Condition.Reason)
+	}
+	if c.Condition.Message != "" {
+		parts = append(parts, c.Condition.Message)
+	}
+
+	return strings.Join(parts, ": ")
+}
+
+// RootCauseReport renders the root causes found below start.
+func RootCauseReport(start *models.Resource, causes []RootCause, now time.Time) string {
+	if len(causes) == 0 {
+		return fmt.Sprintf("%s and all of its loaded descendants are healthy.\n", name(start))
+	}
+
+	var b strings.Builder
+
+	fmt.Fprintf(&b, "Found %d likely root causes below %s:\n", len(causes), name(start))
+
+	for i, c := range causes {
+		fmt.Fprintf(&b, "\n%d. %s\n", i+1, c)
+		if !c.Since.IsZero() {
+			fmt.Fprintf(&b, "   failing for %s\n", duration.HumanDuration(now.Sub(c.Since)))
+		}
+	}
+
+	return b.String()
+}
@@ -0,0 +1,48 @@
+package diagnose
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/nkzk/xrefs/internal/models"
+)
+
+func TestRootCauses(t *testing.T) {
+	root := resource("example.io/v1", "XR", "root", nil,
+		resource("example.io/v1", "App", "app", nil,
+			resource("example.io/v1", "Role", "role", nil),
+		),
+		resource("example.io/v1", "Database", "db", nil),
+		resource("example.io/v1", "Bucket", "bucket", nil),
+	)
+
+	notReady := func(r *models.Resource, reason, since string) {
+		r.Conditions = models.Conditions{{
+			ConditionType:      "Ready",
+			Status:             "False",
+			Reason:             reason,
+			LastTransitionTime: since,
+		}}
+	}
+
+	notReady(&root, "ComposeResources", "2026-01-01T10:00:00Z")
+	notReady(&root.Children[0], "ReconcileError", "2026-01-01T10:00:00Z")
+	notReady(&root.Children[0].Children[0], "Forbidden", "2026-01-01T09:00:00Z")
+	notReady(&root.Children[1], "Creating", "2026-01-01T11:00:00Z")
+	root.Children[2].NotFound = true
+
+	causes := RootCauses(&root)
+	if len(causes) != 3 {
+		t.Fatalf("expected 3 root causes, got %d", len(causes))
+	}
+
+	if got := causes[0].String(); got != "XR/root → App/app → Role/role: Ready=False: Forbidden" {
+		t.Errorf("unexpected first root cause %q", got)
+	}
+	if got := name(causes[1].Resource()); got != "Database/db" {
+		t.Errorf("expected the newer failure second, got %s", got)
+	}
+	if got := causes[2].Explanation(); !strings.Contains(got, "not found") {
+		t.Errorf("expected the missing resource last, got %q", got)
+	}
+}
@@ -0,0 +1,254 @@
+package diff
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Op is the kind of change a Line represents.
+type Op int
+
+const (
+	Equal Op = iota
+	Insert
+	Delete
+)
+
+// Line is a single line of a line-based diff.
+type Line struct {
+	Op   Op
+	Text string
+}
+
+// Lines returns the edit script that turns a into b, using the Myers
+// algorithm. Common prefixes and suffixes are trimmed before diffing, which
+// keeps the common case of a few changed status fields cheap.
+func Lines(a, b []string) []Line {
+	prefix := 0
+	for prefix < len(a) && prefix < len(b) && a[prefix] == b[prefix] {
+		prefix++
+	}
+
+	suffix := 0
+	for suffix < len(a)-prefix && suffix < len(b)-prefix &&
+		a[len(a)-1-suffix] == b[len(b)-1-suffix] {
+		suffix++
+	}
+
+	out := make([]Line, 0, len(a)+len(b))
+	for _, l := range a[:prefix] {
+		out = append(out, Line{Op: Equal, Text: l})
+	}
+
+	out = append(out, myers(a[prefix:len(a)-suffix], b[prefix:len(b)-suffix])...)
+
+	for _, l := range a[len(a)-suffix:] {
+		out = append(out, Line{Op: Equal, Text: l})
+	}
+
+	return out
+}
+
+// Changed returns, for every line in b, whether it was inserted or modified
+// compared to a.
+func Changed(a, b []string) []bool {
+	changed := make([]bool, 0, len(b))
+	for _, l := range Lines(a, b) {
+		switch l.Op {
+		case Equal:
+			changed = append(changed, false)
+		case Insert:
+			changed = append(changed, true)
+		}
+	}
+	return changed
+}
+
+func myers(a, b []string) []Line {
+	n, m := len(a), len(b)
+	if n == 0 && m == 0 {
+		return nil
+	}
+
+	max := n + m
+	offset := max + 1
+	v := make([]int, 2*max+2)
+	var trace [][]int
+
+	for d := 0; d <= max; d++ {
+		snapshot := make([]int, len(v))
+		copy(snapshot, v)
+		trace = append(trace, snapshot)
+
+		for k := -d; k <= d; k += 2 {
+			var x int
+			if k == -d || (k != d && v[offset+k-1] < v[offset+k+1]) {
+				x = v[offset+k+1]
+			} else {
+				x = v[offset+k-1] + 1
+			}
+			y := x - k
+
+			for x < n && y < m && a[x] == b[y] {
+				x++
+				y++
+			}
+
+			v[offset+k] = x
+
+			if x >= n && y >= m {
+				return backtrack(a, b, trace, offset, d)
+			}
+		}
+	}
+
+	return nil
+}
+
+func backtrack(a, b []string, trace [][]int, offset, d int) []Line {
+	x, y := len(a), len(b)
+	var rev []Line
+
+	for ; d > 0; d-- {
+		v := trace[d]
+		k := x - y
+
+		var prevK int
+		if k == -d || (k != d && v[offset+k-1] < v[offset+k+1]) {
+			prevK = k + 1
+		} else {
+			prevK = k - 1
+		}
+
+		prevX := v[offset+prevK]
+		prevY := prevX - prevK
+
+		for x > prevX && y > prevY {
+			x--
+			y--
+			rev = append(rev, Line{Op: Equal, Text: a[x]})
+		}
+
+		if x == prevX {
+			y--
+			rev = append(rev, Line{Op: Insert, Text: b[y]})
+		} else {
+			x--
+			rev = append(rev, Line{Op: Delete, Text: a[x]})
+		}
+	}
+
+	for x > 0 && y > 0 {
+		x--
+		y--
+		rev = append(rev, Line{Op: Equal, Text: a[x]})
+	}
+
+	out := make([]Line, len(rev))
+	for i, l := range rev {
+		out[len(rev)-1-i] = l
+	}
+	return out
+}
+
+// Unified renders the difference between a and b in unified diff format with
+// the given number of context lines. It returns an empty string when a and b
+// are equal.
+func Unified(a, b []string, fromName, toName string, context int) string {
+	lines := Lines(a, b)
+
+	var hunks [][2]int // [start, end) indices into lines
+	for i := 0; i < len(lines); i++ {
+		if lines[i].Op == Equal {
+			continue
+		}
+
+		start := max(i-context, 0)
+		end := i
+		for end < len(lines) {
+			if lines[end].Op != Equal {
+				end++
+				continue
+			}
+
+			// extend over a run of equal lines only if another change
+			// follows within 2*context lines, so hunks are merged
+			next := end
+			for next < len(lines) && lines[next].Op == Equal {
+				next++
+			}
+			if next < len(lines) && next-end <= 2*context {
+				end = next
+				continue
+			}
+			end = min(end+context, len(lines))
+			break
+		}
+
+		if n := len(hunks); n > 0 && hunks[n-1][1] >= start {
+			hunks[n-1][1] = end
+		} else {
+			hunks = append(hunks, [2]int{start, end})
+		}
+		i = end
+	}
+
+	if len(hunks) == 0 {
+		return ""
+	}
+
+	var sb strings.Builder
+	fmt.Fprintf(&sb, "--- %s\n+++ %s\n", fromName, toName)
+
+	aLine, bLine, pos := 0, 0, 0
+	for _, h := range hunks {
+		for ; pos < h[0]; pos++ {
+			aLine, bLine = advance(lines[pos].Op, aLine, bLine)
+		}
+
+		aCount, bCount := 0, 0
+		for _, l := range lines[h[0]:h[1]] {
+			aCount, bCount = advance(l.Op, aCount, bCount)
+		}
+
+		fmt.Fprintf(&sb, "@@ -%s +%s @@\n", hunkRange(aLine, aCount), hunkRange(bLine, bCount))
+
+		for ; pos < h[1]; pos++ {
+			l := lines[pos]
+			switch l.Op {
+			case Equal:
+				sb.WriteString(" ")
+			case Insert:
+				sb.WriteString("+")
+			case Delete:
+				sb.WriteString("-")
+			}
+			sb.WriteString(l.Text)
+			sb.WriteString("\n")
+			aLine, bLine = advance(l.Op, aLine, bLine)
+		}
+	}
+
+	return sb.String()
+}
+
+func advance(op Op, a, b int) (int, int) {
+	switch op {
+	case Equal:
+		return a + 1, b + 1
+	case Insert:
+		return a, b + 1
+	default:
+		return a + 1, b
+	}
+}
+
+func hunkRange(start, count int) string {
+	if count == 0 {
+		return fmt.Sprintf("%d,0", start)
+	}
+	if count == 1 {
+		return fmt.Sprintf("%d", start+1)
+	}
+	return fmt.Sprintf("%d,%d", start+1, count)
+}
@@ -0,0 +1,99 @@
+package diff
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestLines(t *testing.T) {
+	tests := []struct {
+		name string
+		a    string
+		b    string
+		want []Line
+	}{
+		{
+			name: "equal",
+			a:    "a\nb",
+			b:    "a\nb",
+			want: []Line{{Equal, "a"}, {Equal, "b"}},
+		},
+		{
+			name: "changed status field",
+			a:    "status:\n  ready: \"False\"\n  reason: Creating",
+			b:    "status:\n  ready: \"True\"\n  reason: Available",
+			want: []Line{
+				{Equal, "status:"},
+				{Delete, "  ready: \"False\""},
+				{Delete, "  reason: Creating"},
+				{Insert, "  ready: \"True\""},
+				{Insert, "  reason: Available"},
+			},
+		},
+		{
+			name: "insert in the middle",
+			a:    "a\nc",
+			b:    "a\nb\nc",
+			want: []Line{{Equal, "a"}, {Insert, "b"}, {Equal, "c"}},
+		},
+		{
+			name: "delete everything",
+			a:    "a\nb",
+			b:    "",
+			want: []Line{{Delete, "a"}, {Delete, "b"}},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			got := Lines(split(test.a), split(test.b))
+			if !reflect.DeepEqual(got, test.want) {
+				t.Errorf("got %v want %v", got, test.want)
+			}
+		})
+	}
+}
+
+func TestChanged(t *testing.T) {
+	got := Changed(split("a\nb\nc"), split("a\nx\nc\nd"))
+	want := []bool{false, true, false, true}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("got %v want %v", got, want)
+	}
+}
+
+func split(s string) []string {
+	if s == "" {
+		return nil
+	}
+	return strings.Split(s, "\n")
+}
+
+func TestUnified(t *testing.T) {
+	a := split("a\nb\nc\nd\ne\nf\ng\nh\ni")
+	b := split("a\nb\nc\nD\ne\nf\ng\nh\ni\nj")
+
+	want := `--- old
++++ new
+@@ -2,5 +2,5 @@
+ b
+ c
+-d
++D
+ e
+ f
+@@ -8,2 +8,3 @@
+ h
+ i
++j
+`
+
+	if got := Unified(a, b, "old", "new", 2); got != want {
+		t.Errorf("got\n%s\nwant\n%s", got, want)
+	}
+
+	if got := Unified(a, a, "old", "new", 2); got != "" {
+		t.Errorf("expected empty diff for equal input, got\n%s", got)
+	}
+}
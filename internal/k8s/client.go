@@ -3,16 +3,29 @@ package k8s
 import (
 	"context"
 	"fmt"
+	"sort"
+	"time"
 
 	v1 "k8s.io/api/core/v1"
 	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/labels"
 	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/types"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 )
 
+// FieldManager is the field manager used for changes made by xrefs.
+const FieldManager = "xrefs"
+
 type Client interface {
 	GetUnstructured(ctx context.Context, ref *v1.ObjectReference) (*unstructured.Unstructured, error)
+	Apply(ctx context.Context, obj *unstructured.Unstructured) error
+	MergePatch(ctx context.Context, ref *v1.ObjectReference, patch []byte) error
+	Delete(ctx context.Context, ref *v1.ObjectReference) error
+	Events(ctx context.Context, ref *v1.ObjectReference) ([]v1.Event, error)
+	ListObjects(ctx context.Context, ref *v1.ObjectReference, selector string) ([]unstructured.Unstructured, error)
 }
 
 type K8sClient struct {
@@ -44,6 +57,115 @@ func (c K8sClient) GetUnstructured(ctx context.Context, root *v1.ObjectReference
 	return result, err
 }
 
+// Apply applies the object with server-side apply, without forcing ownership of
+// conflicting fields so conflicts are returned to the caller.
+func (c K8sClient) Apply(ctx context.Context, obj *unstructured.Unstructured) error {
+	return c.Client.Apply(
+		ctx,
+		client.ApplyConfigurationFromUnstructured(obj),
+		client.FieldOwner(FieldManager),
+	)
+}
+
+// MergePatch applies a JSON merge patch to the referenced object.
+func (c K8sClient) MergePatch(ctx context.Context, ref *v1.ObjectReference, patch []byte) error {
+	obj := &unstructured.Unstructured{}
+	obj.SetGroupVersionKind(ref.GroupVersionKind())
+	obj.SetName(ref.Name)
+	obj.SetNamespace(ref.Namespace)
+
+	return c.Client.Patch(
+		ctx,
+		obj,
+		client.RawPatch(types.MergePatchType, patch),
+		client.FieldOwner(FieldManager),
+	)
+}
+
+// Delete deletes the referenced object, letting the garbage collector remove
+// its dependents in the background like kubectl does.
+func (c K8sClient) Delete(ctx context.Context, ref *v1.ObjectReference) error {
+	obj := &unstructured.Unstructured{}
+	obj.SetGroupVersionKind(ref.GroupVersionKind())
+	obj.SetName(ref.Name)
+	obj.SetNamespace(ref.Namespace)
+
+	return c.Client.Delete(ctx, obj, client.PropagationPolicy(metav1.DeletePropagationBackground))
+}
+
+// Events lists the events about the referenced object, oldest first. Events
+// are matched on the UID of the object when ref has one, else on its
+// apiVersion, so objects of the same kind and name in other groups are left
+// out.
+func (c K8sClient) Events(ctx context.Context, ref *v1.ObjectReference) ([]v1.Event, error) {
+	fields := client.MatchingFields{
+		"involvedObject.kind": ref.Kind,
+		"involvedObject.name": ref.Name,
+	}
+	if ref.UID != "" {
+		fields["involvedObject.uid"] = string(ref.UID)
+	} else {
+		fields["involvedObject.apiVersion"] = ref.APIVersion
+	}
+
+	list := &v1.EventList{}
+	err := c.Client.List(ctx, list, client.InNamespace(ref.Namespace), fields)
+	if err != nil {
+		return nil, err
+	}
+
+	events := list.Items
+	sort.SliceStable(events, func(i, j int) bool {
+		return EventTime(events[i]).Before(EventTime(events[j]))
+	})
+
+	return events, nil
+}
+
+// ListObjects lists the objects of the kind of ref matching a label selector,
+// in the namespace of ref, or in all namespaces when it is empty.
+func (c K8sClient) ListObjects(ctx context.Context, ref *v1.ObjectReference, selector string) ([]unstructured.Unstructured, error) {
+	sel, err := labels.Parse(selector)
+	if err != nil {
+		return nil, err
+	}
+
+	list := &unstructured.UnstructuredList{}
+	list.SetGroupVersionKind(ref.GroupVersionKind().GroupVersion().WithKind(ref.Kind + "List"))
+
+	opts := []client.ListOption{client.MatchingLabelsSelector{Selector: sel}}
+	if ref.Namespace != "" {
+		opts = append(opts, client.InNamespace(ref.Namespace))
+	}
+
+	if err := c.Client.List(ctx, list, opts...); err != nil {
+		return nil, err
+	}
+
+	objects := list.Items
+	sort.SliceStable(objects, func(i, j int) bool {
+		if objects[i].GetNamespace() != objects[j].GetNamespace() {
+			return objects[i].GetNamespace() < objects[j].GetNamespace()
+		}
+		return objects[i].GetName() < objects[j].GetName()
+	})
+
+	return objects, nil
+}
+
+// EventTime returns when an event was last seen, falling back to when it
+// was created for events that do not set a timestamp.
+func EventTime(e v1.Event) time.Time {
+	switch {
+	case !e.LastTimestamp.IsZero():
+		return e.LastTimestamp.Time
+	case !e.EventTime.IsZero():
+		return e.EventTime.Time
+	default:
+		return e.CreationTimestamp.Time
+	}
+}
+
 type MockClient struct{}
 
 func NewMockClient() *MockClient {
@@ -89,3 +211,30 @@ func (c MockClient) GetUnstructured(ctx context.Context, r *v1.ObjectReference)
 		))
 	}
 }
+
+func (c MockClient) Apply(ctx context.Context, obj *unstructured.Unstructured) error {
+	return nil
+}
+
+func (c MockClient) MergePatch(ctx context.Context, ref *v1.ObjectReference, patch []byte) error {
+	return nil
+}
+
+func (c MockClient) Delete(ctx context.Context, ref *v1.ObjectReference) error {
+	return nil
+}
+
+func (c MockClient) Events(ctx context.Context, ref *v1.ObjectReference) ([]v1.Event, error) {
+	if ref.Kind != mockXRKind {
+		return nil, nil
+	}
+	return mockXREvents(), nil
+}
+
+func (c MockClient) ListObjects(ctx context.Context, ref *v1.ObjectReference, selector string) ([]unstructured.Unstructured, error) {
+	u, err := c.GetUnstructured(ctx, ref)
+	if err != nil {
+		return nil, err
+	}
+	return []unstructured.Unstructured{*u}, nil
+}
@@ -0,0 +1,95 @@
+package k8s
+
+import (
+	"context"
+	"slices"
+	"sort"
+
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/client-go/discovery"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// completionLimit is the number of objects listed to complete a name.
+const completionLimit = 500
+
+// ResourceTypes returns the resource types served by the cluster in the
+// TYPE[.GROUP] format accepted by MappingFor, like `pods` or
+// `xrs.example.io`.
+func ResourceTypes(d discovery.DiscoveryInterface) []string {
+	seen := map[string]bool{}
+	servedResources(d, func(gv schema.GroupVersion, r metav1.APIResource) {
+		seen[typeName(gv, r)] = true
+	})
+
+	types := make([]string, 0, len(seen))
+	for t := range seen {
+		types = append(types, t)
+	}
+	sort.Strings(types)
+
+	return types
+}
+
+// servedResources calls fn for the preferred version of every resource that
+// can be read. Groups that fail discovery are skipped.
+func servedResources(d discovery.DiscoveryInterface, fn func(schema.GroupVersion, metav1.APIResource)) {
+	lists, _ := d.ServerPreferredResources()
+
+	for _, list := range lists {
+		gv, err := schema.ParseGroupVersion(list.GroupVersion)
+		if err != nil {
+			continue
+		}
+
+		for _, r := range list.APIResources {
+			if slices.Contains(r.Verbs, "get") {
+				fn(gv, r)
+			}
+		}
+	}
+}
+
+// typeName returns the TYPE[.GROUP] name of a resource.
+func typeName(gv schema.GroupVersion, r metav1.APIResource) string {
+	if gv.Group == "" {
+		return r.Name
+	}
+	return r.Name + "." + gv.Group
+}
+
+// ObjectNames lists the names of the objects of a mapping, in namespace for
+// namespaced resources.
+func ObjectNames(ctx context.Context, c client.Client, mapping *meta.RESTMapping, namespace string) ([]string, error) {
+	resources, err := ListResources(ctx, c, mapping, namespace, client.Limit(completionLimit))
+	if err != nil {
+		return nil, err
+	}
+
+	names := make([]string, 0, len(resources))
+	for _, r := range resources {
+		names = append(names, r.Ref.Name)
+	}
+	sort.Strings(names)
+
+	return names, nil
+}
+
+// NamespaceNames lists the names of the namespaces of the cluster.
+func NamespaceNames(ctx context.Context, c client.Client) ([]string, error) {
+	list := &v1.NamespaceList{}
+	if err := c.List(ctx, list, client.Limit(completionLimit)); err != nil {
+		return nil, err
+	}
+
+	names := make([]string, 0, len(list.Items))
+	for _, ns := range list.Items {
+		names = append(names, ns.Name)
+	}
+	sort.Strings(names)
+
+	return names, nil
+}
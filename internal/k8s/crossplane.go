@@ -0,0 +1,211 @@
+package k8s
+
+import (
+	"strings"
+	"time"
+
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+)
+
+const (
+	// CrossplanePausedAnnotation pauses reconciliation of Crossplane resources
+	// when set to "true".
+	CrossplanePausedAnnotation = "crossplane.io/paused"
+
+	// ReconcileRequestAnnotation is bumped to trigger a reconcile of resources
+	// whose controllers have no dedicated annotation for it. Crossplane
+	// reconciles on any change to the object, so touching an annotation is enough.
+	ReconcileRequestAnnotation = "xrefs.nkzk.github.io/reconcile-requested-at"
+)
+
+// IsCrossplaneResource reports whether u looks like a Crossplane XR, claim or
+// managed resource.
+func IsCrossplaneResource(u *unstructured.Unstructured) bool {
+	if u == nil {
+		return false
+	}
+
+	if strings.HasSuffix(u.GroupVersionKind().Group, "crossplane.io") {
+		return true
+	}
+
+	for _, field := range [][]string{
+		{"spec", "crossplane"},        // v2 XR
+		{"spec", "resourceRefs"},      // v1 XR
+		{"spec", "resourceRef"},       // claim
+		{"spec", "compositionRef"},    // XR or claim
+		{"spec", "forProvider"},       // managed resource
+		{"spec", "providerConfigRef"}, // managed resource
+	} {
+		if _, ok, _ := unstructured.NestedFieldNoCopy(u.Object, field...); ok {
+			return true
+		}
+	}
+
+	_, ok := u.GetLabels()["crossplane.io/composite"]
+	return ok
+}
+
+// CrossplanePaused reports whether reconciliation of u is paused.
+func CrossplanePaused(u *unstructured.Unstructured) bool {
+	if u == nil {
+		return false
+	}
+	return u.GetAnnotations()[CrossplanePausedAnnotation] == "true"
+}
+
+// CrossplanePausePatch returns a merge patch that pauses or unpauses u.
+func CrossplanePausePatch(paused bool) []byte {
+	var value any // nil removes the annotation
+	if paused {
+		value = "true"
+	}
+
+	return mustMarshal(map[string]any{
+		"metadata": map[string]any{
+			"annotations": map[string]any{
+				CrossplanePausedAnnotation: value,
+			},
+		},
+	})
+}
+
+// CrossplaneReconcilePatch returns a merge patch that triggers a reconcile by
+// bumping ReconcileRequestAnnotation to t.
+func CrossplaneReconcilePatch(t time.Time) []byte {
+	return mustMarshal(map[string]any{
+		"metadata": map[string]any{
+			"annotations": map[string]any{
+				ReconcileRequestAnnotation: t.Format(time.RFC3339Nano),
+			},
+		},
+	})
+}
+
+// Usage is the parsed spec of a Crossplane Usage.
+type Usage struct {
+	Name string
+
+	// Of is the resource being used, which the Usage protects from deletion.
+	Of v1.ObjectReference
+
+	// By is the resource using Of. It is empty for Usages that only give a reason.
+	By v1.ObjectReference
+
+	Reason string
+}
+
+// ParseUsage parses a Crossplane Usage. Resource refs without a namespace get
+// the namespace of the Usage, which is where namespaced Usages resolve them.
+func ParseUsage(u *unstructured.Unstructured) (Usage, bool) {
+	if u == nil || u.GetKind() != "Usage" {
+		return Usage{}, false
+	}
+
+	of, ok := usageRef(u, "of")
+	if !ok {
+		return Usage{}, false
+	}
+
+	by, _ := usageRef(u, "by")
+	reason, _, _ := unstructured.NestedString(u.Object, "spec", "reason")
+
+	return Usage{
+		Name:   u.GetName(),
+		Of:     of,
+		By:     by,
+		Reason: reason,
+	}, true
+}
+
+func usageRef(u *unstructured.Unstructured, field string) (v1.ObjectReference, bool) {
+	data, _, _ := unstructured.NestedMap(u.Object, "spec", field)
+	if data == nil {
+		return v1.ObjectReference{}, false
+	}
+
+	apiVersion, _ := data["apiVersion"].(string)
+	kind, _ := data["kind"].(string)
+
+	name := ""
+	namespace := ""
+	if resourceRef, ok := data["resourceRef"].(map[string]any); ok {
+		name, _ = resourceRef["name"].(string)
+		namespace, _ = resourceRef["namespace"].(string)
+	} else {
+		name, _ = data["name"].(string)
+	}
+
+	if kind == "" || name == "" {
+		return v1.ObjectReference{}, false
+	}
+
+	if namespace == "" {
+		namespace = u.GetNamespace()
+	}
+
+	return v1.ObjectReference{
+		APIVersion: apiVersion,
+		Kind:       kind,
+		Name:       name,
+		Namespace:  namespace,
+	}, true
+}
+
+// SameObject reports whether a and b refer to the same object. Versions are
+// ignored, and namespaces are only compared when both are set.
+func SameObject(a, b *v1.ObjectReference) bool {
+	if a == nil || b == nil {
+		return false
+	}
+
+	if a.Kind != b.Kind || a.Name != b.Name {
+		return false
+	}
+
+	if a.GroupVersionKind().Group != b.GroupVersionKind().Group {
+		return false
+	}
+
+	return a.Namespace == "" || b.Namespace == "" || a.Namespace == b.Namespace
+}
+
+// ManagementPolicies returns the deletionPolicy and managementPolicies of a
+// managed resource, with Crossplane's defaults applied. ok is false for
+// resources that are not managed resources.
+func ManagementPolicies(u *unstructured.Unstructured) (deletionPolicy string, managementPolicies []string, ok bool) {
+	if u == nil {
+		return "", nil, false
+	}
+
+	if _, found, _ := unstructured.NestedFieldNoCopy(u.Object, "spec", "forProvider"); !found {
+		return "", nil, false
+	}
+
+	deletionPolicy, _, _ = unstructured.NestedString(u.Object, "spec", "deletionPolicy")
+	if deletionPolicy == "" {
+		deletionPolicy = "Delete"
+	}
+
+	managementPolicies, _, _ = unstructured.NestedStringSlice(u.Object, "spec", "managementPolicies")
+	if len(managementPolicies) == 0 {
+		managementPolicies = []string{"*"}
+	}
+
+	return deletionPolicy, managementPolicies, true
+}
+
+// DeletesExternalResource reports whether deleting a managed resource with the
+// given policies also deletes the external resource.
+func DeletesExternalResource(deletionPolicy string, managementPolicies []string) bool {
+	for _, p := range managementPolicies {
+		if p == "*" {
+			return deletionPolicy != "Orphan"
+		}
+		if p == "Delete" {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,95 @@
+package k8s
+
+import (
+	"encoding/json"
+	"time"
+
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+)
+
+// FluxReconcileAnnotation is the annotation Flux controllers watch to
+// reconcile an object outside of its interval.
+const FluxReconcileAnnotation = "reconcile.fluxcd.io/requestedAt"
+
+// IsFluxResource reports whether u is a Flux Kustomization, HelmRelease or source.
+func IsFluxResource(u *unstructured.Unstructured) bool {
+	if u == nil {
+		return false
+	}
+
+	switch u.GroupVersionKind().Group {
+	case "kustomize.toolkit.fluxcd.io",
+		"helm.toolkit.fluxcd.io",
+		"source.toolkit.fluxcd.io":
+		return true
+	}
+
+	return false
+}
+
+// FluxSuspended reports whether reconciliation of the Flux object is suspended.
+func FluxSuspended(u *unstructured.Unstructured) bool {
+	if !IsFluxResource(u) {
+		return false
+	}
+
+	suspended, _, _ := unstructured.NestedBool(u.Object, "spec", "suspend")
+	return suspended
+}
+
+// IsFluxKustomization reports whether u is a Flux Kustomization.
+func IsFluxKustomization(u *unstructured.Unstructured) bool {
+	return u != nil && u.GroupVersionKind().Group == "kustomize.toolkit.fluxcd.io" && u.GetKind() == "Kustomization"
+}
+
+// FluxPrunes reports whether the Kustomization u garbage collects the objects
+// it applied when it is deleted, which it only does with spec.prune set.
+func FluxPrunes(u *unstructured.Unstructured) bool {
+	if !IsFluxKustomization(u) {
+		return false
+	}
+
+	prune, _, _ := unstructured.NestedBool(u.Object, "spec", "prune")
+	return prune
+}
+
+// FluxReconcilePatch returns a merge patch requesting a reconcile at t, like
+// `flux reconcile` does.
+func FluxReconcilePatch(t time.Time) []byte {
+	return mustMarshal(map[string]any{
+		"metadata": map[string]any{
+			"annotations": map[string]any{
+				FluxReconcileAnnotation: t.Format(time.RFC3339Nano),
+			},
+		},
+	})
+}
+
+// FluxSuspendPatch returns a merge patch that suspends or resumes the object.
+// Resuming also requests a reconcile, like `flux resume` does.
+func FluxSuspendPatch(suspend bool, t time.Time) []byte {
+	patch := map[string]any{
+		"spec": map[string]any{
+			"suspend": suspend,
+		},
+	}
+
+	if !suspend {
+		patch["metadata"] = map[string]any{
+			"annotations": map[string]any{
+				FluxReconcileAnnotation: t.Format(time.RFC3339Nano),
+			},
+		}
+	}
+
+	return mustMarshal(patch)
+}
+
+// mustMarshal marshals patches built from plain maps, which cannot fail.
+func mustMarshal(v any) []byte {
+	b, err := json.Marshal(v)
+	if err != nil {
+		panic(err)
+	}
+	return b
+}
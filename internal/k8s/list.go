@@ -0,0 +1,72 @@
+package k8s
+
+import (
+	"context"
+	"sort"
+
+	"github.com/nkzk/xrefs/internal/models"
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/client-go/discovery"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// CategoryTypes returns the resource types in a category, like `composite`
+// or `flux`, in the TYPE.GROUP format accepted by MappingFor.
+func CategoryTypes(d discovery.DiscoveryInterface, category string) []string {
+	var types []string
+	servedResources(d, func(gv schema.GroupVersion, r metav1.APIResource) {
+		for _, c := range r.Categories {
+			if c == category {
+				types = append(types, typeName(gv, r))
+				return
+			}
+		}
+	})
+	sort.Strings(types)
+
+	return types
+}
+
+// ListResources lists the objects of a mapping as roots of a tree, in
+// namespace, or in all namespaces when it is empty.
+func ListResources(ctx context.Context, c client.Client, mapping *meta.RESTMapping, namespace string, opts ...client.ListOption) ([]*models.Resource, error) {
+	list := &unstructured.UnstructuredList{}
+	list.SetGroupVersionKind(mapping.GroupVersionKind.GroupVersion().WithKind(mapping.GroupVersionKind.Kind + "List"))
+
+	if mapping.Scope.Name() == meta.RESTScopeNameNamespace && namespace != "" {
+		opts = append(opts, client.InNamespace(namespace))
+	}
+
+	if err := c.List(ctx, list, opts...); err != nil {
+		return nil, err
+	}
+
+	resources := make([]*models.Resource, 0, len(list.Items))
+	for i := range list.Items {
+		u := &list.Items[i]
+		u.SetGroupVersionKind(mapping.GroupVersionKind)
+
+		r := models.NewResource(nil, u, &v1.ObjectReference{
+			APIVersion: u.GetAPIVersion(),
+			Kind:       u.GetKind(),
+			Name:       u.GetName(),
+			Namespace:  u.GetNamespace(),
+		})
+		r.Conditions = ParseConditions(u)
+		resources = append(resources, r)
+	}
+
+	sort.SliceStable(resources, func(i, j int) bool {
+		a, b := resources[i].Ref, resources[j].Ref
+		if a.Namespace != b.Namespace {
+			return a.Namespace < b.Namespace
+		}
+		return a.Name < b.Name
+	})
+
+	return resources, nil
+}
@@ -1,6 +1,12 @@
 package k8s
 
-import "k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+import (
+	"time"
+
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+)
 
 const (
 	mockFluxKustomizationKind    = "Kustomization"
@@ -542,3 +548,23 @@ func mockProviderConfigUsage() *unstructured.Unstructured {
 		},
 	}
 }
+
+func mockXREvents() []v1.Event {
+	now := time.Now()
+	return []v1.Event{
+		{
+			Type:          v1.EventTypeNormal,
+			Reason:        "SelectComposition",
+			Message:       "Successfully selected composition: example",
+			Count:         1,
+			LastTimestamp: metav1.NewTime(now.Add(-10 * time.Minute)),
+		},
+		{
+			Type:          v1.EventTypeWarning,
+			Reason:        "ComposeResources",
+			Message:       "cannot compose resources: cannot get DoesNotExist example: not found",
+			Count:         12,
+			LastTimestamp: metav1.NewTime(now.Add(-30 * time.Second)),
+		},
+	}
+}
@@ -0,0 +1,109 @@
+package k8s
+
+import (
+	"context"
+
+	"github.com/nkzk/xrefs/internal/models"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+)
+
+// A selection is the synthetic root of a set of objects, like all claims
+// labelled team=payments. Its spec holds the query, so it can be inspected
+// like any other resource of the tree.
+const (
+	SelectionAPIVersion = "xrefs.nkzk.github.io/v1alpha1"
+	SelectionKind       = "Selection"
+)
+
+// NewSelection returns the root of the objects of types matching a label
+// selector. Each type sets the apiVersion and kind of the objects and the
+// namespace they are listed in, empty for all namespaces.
+func NewSelection(name, namespace, selector string, types []corev1.ObjectReference) *models.Resource {
+	resources := make([]any, 0, len(types))
+	for _, t := range types {
+		resources = append(resources, map[string]any{
+			"apiVersion": t.APIVersion,
+			"kind":       t.Kind,
+			"namespace":  t.Namespace,
+		})
+	}
+
+	u := &unstructured.Unstructured{Object: map[string]any{
+		"spec": map[string]any{
+			"selector":  selector,
+			"resources": resources,
+		},
+	}}
+	u.SetAPIVersion(SelectionAPIVersion)
+	u.SetKind(SelectionKind)
+	u.SetName(name)
+	u.SetNamespace(namespace)
+
+	return models.NewResource(nil, u, &corev1.ObjectReference{
+		APIVersion: SelectionAPIVersion,
+		Kind:       SelectionKind,
+		Name:       name,
+		Namespace:  namespace,
+	})
+}
+
+// IsSelection reports whether r is the synthetic root of a selection.
+func IsSelection(r *models.Resource) bool {
+	return r.Ref != nil && r.Ref.APIVersion == SelectionAPIVersion && r.Ref.Kind == SelectionKind
+}
+
+// SelectionQuery returns the label selector and the types of a selection.
+func SelectionQuery(r *models.Resource) (string, []corev1.ObjectReference) {
+	selector, _, _ := unstructured.NestedString(r.Unstructured.Object, "spec", "selector")
+	resources, _, _ := unstructured.NestedSlice(r.Unstructured.Object, "spec", "resources")
+
+	types := make([]corev1.ObjectReference, 0, len(resources))
+	for _, res := range resources {
+		m, ok := res.(map[string]any)
+		if !ok {
+			continue
+		}
+
+		var t corev1.ObjectReference
+		if err := runtime.DefaultUnstructuredConverter.FromUnstructured(m, &t); err == nil {
+			types = append(types, t)
+		}
+	}
+
+	return selector, types
+}
+
+// refreshSelection lists the objects of a selection again as its children,
+// so objects joining or leaving it are shown, and refreshes them.
+func refreshSelection(ctx context.Context, r *models.Resource, kClient Client, all bool) error {
+	selector, types := SelectionQuery(r)
+
+	var children []models.Resource
+	for i := range types {
+		objects, err := kClient.ListObjects(ctx, &types[i], selector)
+		if err != nil {
+			return err
+		}
+
+		for _, o := range objects {
+			children = append(children, *models.NewResource(nil, nil, &corev1.ObjectReference{
+				APIVersion: o.GetAPIVersion(),
+				Kind:       o.GetKind(),
+				Name:       o.GetName(),
+				Namespace:  o.GetNamespace(),
+			}))
+		}
+	}
+
+	mergeChildren(r, children)
+
+	if !r.Expanded && !all {
+		return nil
+	}
+
+	r.ChildrenLoaded = true
+
+	return refreshChildren(ctx, r, kClient, all)
+}
@@ -0,0 +1,365 @@
+package k8s
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/nkzk/xrefs/internal/models"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+)
+
+// Refresh fetches a Resource and the children of expanded resources, updating
+// them in place. Condition transitions are recorded only for the resources
+// fetched, so the children of collapsed resources have no timeline until
+// they are shown.
+func Refresh(ctx context.Context, r *models.Resource, kClient Client) error {
+	return refresh(ctx, r, kClient, false)
+}
+
+// RefreshAll fetches a Resource and all of its descendants, also those below
+// collapsed resources, without expanding them.
+func RefreshAll(ctx context.Context, r *models.Resource, kClient Client) error {
+	return refresh(ctx, r, kClient, true)
+}
+
+// refresh fetches r and the children of expanded resources, or of all
+// resources when all is set.
+func refresh(ctx context.Context, r *models.Resource, kClient Client, all bool) error {
+	if IsSelection(r) {
+		return refreshSelection(ctx, r, kClient, all)
+	}
+
+	current, err := kClient.GetUnstructured(ctx, r.Ref)
+	if err != nil {
+		if apierrors.IsNotFound(err) {
+			r.NotFound = true
+			// children may outlive their parent while a deletion is in progress
+			return refreshChildren(ctx, r, kClient, all)
+		}
+
+		return err
+	}
+
+	r.NotFound = false
+
+	// the first observation is the baseline for condition transitions
+	observed := len(r.History) > 0
+
+	// Update unstructured
+	r.Unstructured = current
+	r.Record(current)
+
+	// Update Resource conditions
+	freshConditions := ParseConditions(current)
+
+	if observed {
+		r.RecordTransitions(freshConditions, time.Now())
+	}
+	r.Conditions = freshConditions
+
+	loadResourceChildren(r)
+
+	if !r.Expanded && !all {
+		return nil
+	}
+
+	r.ChildrenLoaded = true
+
+	return refreshChildren(ctx, r, kClient, all)
+}
+
+// ParseConditions returns the status conditions of an object.
+func ParseConditions(u *unstructured.Unstructured) models.Conditions {
+	parsed := models.Conditions{}
+	conditions, ok, err := unstructured.NestedSlice(u.Object, "status", "conditions")
+	if !ok || err != nil {
+		return parsed
+	}
+
+	for _, c := range conditions {
+		m, ok := c.(map[string]any)
+		if !ok {
+			continue
+		}
+
+		var condition models.Condition
+		if err := runtime.DefaultUnstructuredConverter.FromUnstructured(m, &condition); err == nil {
+			parsed = append(parsed, condition)
+		}
+	}
+
+	return parsed
+}
+
+// refreshChildren refreshes the children of an expanded resource, or of any
+// resource when all is set.
+func refreshChildren(ctx context.Context, r *models.Resource, kClient Client, all bool) error {
+	if !r.Expanded && !all {
+		return nil
+	}
+
+	for i := range r.Children {
+		if err := refresh(ctx, &r.Children[i], kClient, all); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// loads resource-refs of a root resource to the Children array.
+func loadResourceChildren(root *models.Resource) {
+	var (
+		newChildren []models.Resource
+		ok          bool
+	)
+
+	switch gvk := root.Unstructured.GroupVersionKind(); {
+	case gvk == schema.GroupVersionKind{
+		Group:   "kustomize.toolkit.fluxcd.io",
+		Version: "v1",
+		Kind:    "Kustomization"}:
+		newChildren, ok = kustomizationChildren(root.Unstructured)
+	case gvk.Group == "argoproj.io" && gvk.Kind == "Application":
+		newChildren, ok = applicationChildren(root.Unstructured)
+	default:
+		// assume crossplane XR or claim
+		newChildren, ok = crossplaneChildren(root.Unstructured)
+	}
+
+	if !ok {
+		root.Children = nil
+		return
+	}
+
+	mergeChildren(root, newChildren)
+}
+
+// kustomizationChildren returns the objects of the inventory of a Flux
+// Kustomization.
+func kustomizationChildren(u *unstructured.Unstructured) ([]models.Resource, bool) {
+	entries, ok, err := unstructured.NestedSlice(u.Object, "status", "inventory", "entries")
+	if err != nil || !ok {
+		return nil, false
+	}
+
+	var children []models.Resource
+	for _, e := range entries {
+		m, ok := e.(map[string]any)
+		if !ok {
+			continue
+		}
+
+		// id format: <namespace>_<name>_<group>_<kind>
+		// "v" for version
+		id, _ := m["id"].(string)
+		version, _ := m["v"].(string)
+
+		parts := strings.SplitN(id, "_", 4)
+		if len(parts) < 4 {
+			continue
+		}
+
+		ns, name, group, kind := parts[0], parts[1], parts[2], parts[3]
+
+		apiVersion := version
+		if group != "" {
+			apiVersion = group + "/" + version
+		}
+
+		ref := &corev1.ObjectReference{
+			APIVersion: apiVersion,
+			Kind:       kind,
+			Name:       name,
+			Namespace:  ns,
+		}
+
+		children = append(children, *models.NewResource(nil, nil, ref))
+	}
+
+	return children, true
+}
+
+// applicationChildren returns the objects an Argo CD Application manages,
+// listed in its status.resources.
+func applicationChildren(u *unstructured.Unstructured) ([]models.Resource, bool) {
+	resources, ok, err := unstructured.NestedSlice(u.Object, "status", "resources")
+	if err != nil || !ok {
+		return nil, false
+	}
+
+	var children []models.Resource
+	for _, r := range resources {
+		m, ok := r.(map[string]any)
+		if !ok {
+			continue
+		}
+
+		group, _ := m["group"].(string)
+		version, _ := m["version"].(string)
+		kind, _ := m["kind"].(string)
+		name, _ := m["name"].(string)
+		namespace, _ := m["namespace"].(string)
+		if kind == "" || name == "" || version == "" {
+			continue
+		}
+
+		ref := &corev1.ObjectReference{
+			APIVersion: schema.GroupVersion{Group: group, Version: version}.String(),
+			Kind:       kind,
+			Name:       name,
+			Namespace:  namespace,
+		}
+
+		children = append(children, *models.NewResource(nil, nil, ref))
+	}
+
+	return children, true
+}
+
+// crossplaneChildren returns the resources composed by a Crossplane XR, in
+// spec.crossplane.resourceRefs for v2 and spec.resourceRefs for v1, or the
+// XR of a claim in spec.resourceRef.
+func crossplaneChildren(u *unstructured.Unstructured) ([]models.Resource, bool) {
+	resourceRefs, ok, err := unstructured.NestedSlice(u.Object, "spec", "crossplane", "resourceRefs")
+	if err != nil || !ok {
+		resourceRefs, ok, err = unstructured.NestedSlice(u.Object, "spec", "resourceRefs")
+	}
+	if err != nil || !ok {
+		// the XR of a claim is cluster scoped, so it keeps an empty namespace
+		resourceRef, ok, err := unstructured.NestedMap(u.Object, "spec", "resourceRef")
+		if err != nil || !ok {
+			return nil, false
+		}
+
+		ref := corev1.ObjectReference{}
+		if err := runtime.DefaultUnstructuredConverter.FromUnstructured(resourceRef, &ref); err != nil || ref.Name == "" {
+			return nil, true
+		}
+
+		return []models.Resource{*models.NewResource(nil, nil, &ref)}, true
+	}
+
+	parentNS := u.GetNamespace()
+
+	var children []models.Resource
+	for _, r := range resourceRefs {
+		ref := corev1.ObjectReference{}
+
+		m, ok := r.(map[string]any)
+		if !ok {
+			continue
+		}
+
+		if err := runtime.DefaultUnstructuredConverter.FromUnstructured(m, &ref); err != nil {
+			continue
+		}
+
+		if ref.Namespace == "" && parentNS != "" {
+			ref.Namespace = parentNS
+		}
+
+		children = append(children, *models.NewResource(nil, nil, &ref))
+	}
+
+	return children, true
+}
+
+// mergeChildren replaces the children of root, keeping the state of those
+// it already had, like their ID, expansion and history.
+func mergeChildren(root *models.Resource, newChildren []models.Resource) {
+	existingChildren := make(map[string]*models.Resource)
+	for i := range root.Children {
+		c := &root.Children[i]
+		if c.Ref != nil {
+			existingChildren[refKey(c.Ref)] = c
+		}
+	}
+
+	// Merge: preserve ID/Expanded/ChildrenLoaded/Children state from existing children
+	for i := range newChildren {
+		ref := newChildren[i].Ref
+		if ref != nil {
+			if existing, ok := existingChildren[refKey(ref)]; ok {
+				newChildren[i].ID = existing.ID
+				newChildren[i].Expanded = existing.Expanded
+				newChildren[i].ChildrenLoaded = existing.ChildrenLoaded
+				newChildren[i].Children = existing.Children
+				newChildren[i].Unstructured = existing.Unstructured
+				newChildren[i].History = existing.History
+				newChildren[i].Conditions = existing.Conditions
+				newChildren[i].Transitions = existing.Transitions
+				newChildren[i].ChangedAt = existing.ChangedAt
+				newChildren[i].Error = existing.Error
+				newChildren[i].NotFound = existing.NotFound
+			}
+		}
+	}
+
+	root.Children = newChildren
+}
+
+// LoadTree refreshes root and expands the tree level by level until depth
+// levels below root are loaded, or the whole tree when depth is negative.
+func LoadTree(ctx context.Context, root *models.Resource, kClient Client, depth int) error {
+	if err := Refresh(ctx, root, kClient); err != nil {
+		return err
+	}
+
+	for {
+		expanded := expand(root, 0, depth, map[string]bool{})
+		if len(expanded) == 0 {
+			return nil
+		}
+
+		for _, r := range expanded {
+			if err := Refresh(ctx, r, kClient); err != nil {
+				return err
+			}
+		}
+	}
+}
+
+// expand expands the collapsed resources with children above depth and
+// returns them. Resources that already appear among their ancestors are left
+// collapsed, so reference cycles do not expand forever.
+func expand(r *models.Resource, level, depth int, ancestors map[string]bool) []*models.Resource {
+	if depth >= 0 && level >= depth {
+		return nil
+	}
+
+	key := refKey(r.Ref)
+	if ancestors[key] {
+		return nil
+	}
+
+	if !r.Expanded {
+		if len(r.Children) == 0 {
+			return nil
+		}
+		r.Expanded = true
+		return []*models.Resource{r}
+	}
+
+	ancestors[key] = true
+	defer delete(ancestors, key)
+
+	var out []*models.Resource
+	for i := range r.Children {
+		out = append(out, expand(&r.Children[i], level+1, depth, ancestors)...)
+	}
+	return out
+}
+
+func refKey(ref *corev1.ObjectReference) string {
+	if ref == nil {
+		return ""
+	}
+	return fmt.Sprintf("%s/%s/%s/%s", ref.APIVersion, ref.Kind, ref.Namespace, ref.Name)
+}
@@ -20,7 +20,7 @@ import (
 	"sigs.k8s.io/controller-runtime/pkg/client"
 )
 
-func SetupKubeClient(kc, context string, diskCache bool) (clientcmd.ClientConfig, client.WithWatch, meta.RESTMapper, error) {
+func SetupKubeClient(kc, context string, diskCache bool) (clientcmd.ClientConfig, client.WithWatch, meta.RESTMapper, discovery.CachedDiscoveryInterface, error) {
 	loadingRules := clientcmd.NewDefaultClientConfigLoadingRules()
 	if kc != "" {
 		loadingRules.ExplicitPath = kc
@@ -34,7 +34,7 @@ func SetupKubeClient(kc, context string, diskCache bool) (clientcmd.ClientConfig
 
 	kubeconfig, err := cf.ClientConfig()
 	if err != nil {
-		return nil, nil, nil, fmt.Errorf("failed to get kubeconfig: %v", err)
+		return nil, nil, nil, nil, fmt.Errorf("failed to get kubeconfig: %v", err)
 	}
 
 	if kubeconfig.QPS == 0 {
@@ -49,12 +49,12 @@ func SetupKubeClient(kc, context string, diskCache bool) (clientcmd.ClientConfig
 		Scheme: scheme.Scheme,
 	})
 	if err != nil {
-		return nil, nil, nil, fmt.Errorf("failed to init kube client: %v", err)
+		return nil, nil, nil, nil, fmt.Errorf("failed to init kube client: %v", err)
 	}
 
 	discoveryClient, err := discovery.NewDiscoveryClientForConfig(kubeconfig)
 	if err != nil {
-		return nil, nil, nil, fmt.Errorf("failed to set up discovery client: %v", err)
+		return nil, nil, nil, nil, fmt.Errorf("failed to set up discovery client: %v", err)
 	}
 
 	d := memory.NewMemCacheClient(discoveryClient)
@@ -66,13 +66,13 @@ func SetupKubeClient(kc, context string, diskCache bool) (clientcmd.ClientConfig
 			10*time.Minute,
 		)
 		if err != nil {
-			return nil, nil, nil, fmt.Errorf("failed to set up discovery client with disk cache: %v", err)
+			return nil, nil, nil, nil, fmt.Errorf("failed to set up discovery client with disk cache: %v", err)
 		}
 	}
 
 	rmapper := restmapper.NewShortcutExpander(restmapper.NewDeferredDiscoveryRESTMapper(d), d, nil)
 
-	return cf, cl, rmapper, nil
+	return cf, cl, rmapper, d, nil
 }
 
 func getCacheDir() string {
@@ -3,10 +3,13 @@ package k8s
 import (
 	"context"
 	"errors"
+	"sync"
 	"time"
 
 	"github.com/nkzk/xrefs/internal/models"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
 	"k8s.io/apimachinery/pkg/fields"
+	"k8s.io/apimachinery/pkg/labels"
 	"k8s.io/apimachinery/pkg/watch"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 )
@@ -29,6 +32,10 @@ func (w KubernetesResourceWatcher) WatchResource(
 	ctx context.Context,
 	root *models.Resource,
 ) (watch.Interface, error) {
+	if IsSelection(root) {
+		return w.watchSelection(ctx, root)
+	}
+
 	opts := &client.ListOptions{
 		Namespace:     root.Unstructured.GetNamespace(),
 		FieldSelector: fields.OneTermEqualSelector("metadata.name", root.Unstructured.GetName()),
@@ -44,6 +51,80 @@ func (w KubernetesResourceWatcher) WatchResource(
 	return watchClient.Watch(ctx, obj, opts)
 }
 
+// watchSelection watches the objects of every type of a selection. Objects
+// joining or leaving the selection change the root rather than delete it, so
+// every event is passed on as a modification. Events arriving while one is
+// pending are dropped, since a single refresh covers them.
+func (w KubernetesResourceWatcher) watchSelection(
+	ctx context.Context,
+	root *models.Resource,
+) (watch.Interface, error) {
+	watchClient, ok := w.Client.(client.WithWatch)
+	if !ok {
+		return nil, errors.New("client does not support watch")
+	}
+
+	selector, types := SelectionQuery(root)
+	sel, err := labels.Parse(selector)
+	if err != nil {
+		return nil, err
+	}
+
+	var watches []watch.Interface
+	for _, t := range types {
+		list := &unstructured.UnstructuredList{}
+		list.SetGroupVersionKind(t.GroupVersionKind().GroupVersion().WithKind(t.Kind + "List"))
+
+		wi, err := watchClient.Watch(ctx, list, &client.ListOptions{
+			Namespace:     t.Namespace,
+			LabelSelector: sel,
+		})
+		if err != nil {
+			for _, wi := range watches {
+				wi.Stop()
+			}
+			return nil, err
+		}
+		watches = append(watches, wi)
+	}
+
+	events := make(chan watch.Event, 1)
+	proxy := watch.NewProxyWatcher(events)
+	modified := watch.Event{Type: watch.Modified, Object: root.Unstructured.DeepCopy()}
+
+	var wg sync.WaitGroup
+	for _, wi := range watches {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			defer wi.Stop()
+
+			for {
+				select {
+				case _, ok := <-wi.ResultChan():
+					if !ok {
+						return
+					}
+					select {
+					case events <- modified:
+					default: // a refresh is pending already
+					}
+
+				case <-proxy.StopChan():
+					return
+				}
+			}
+		}()
+	}
+
+	go func() {
+		wg.Wait()
+		close(events)
+	}()
+
+	return proxy, nil
+}
+
 type MockResourceWatcher struct{}
 
 func NewMockResourceWatcher() *MockResourceWatcher {
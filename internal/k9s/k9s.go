@@ -93,18 +93,27 @@ func installed(s string) bool {
 }
 
 func getPluginDirectory(input []byte) (string, error) {
+	path, err := getInfoPath(input, "Plugins")
+	if err != nil {
+		return "", errors.New("failed to get k9s plugin directory from k9s info")
+	}
+	return path, nil
+}
+
+// getInfoPath returns the path listed for field in the output of k9s info.
+func getInfoPath(input []byte, field string) (string, error) {
 	scanner := bufio.NewScanner(bytes.NewReader(input))
 	for scanner.Scan() {
-		line := scanner.Text()
-		if strings.Contains(line, "Plugins") {
-			fields := strings.Fields(line)
-			if len(fields) > 1 {
-				return strings.Join(fields[1:], " "), nil
+		line := strings.TrimSpace(scanner.Text())
+		if strings.HasPrefix(line, field+":") {
+			fields := strings.Fields(strings.TrimPrefix(line, field+":"))
+			if len(fields) > 0 {
+				return strings.Join(fields, " "), nil
 			}
 			break
 		}
 	}
-	return "", errors.New("failed to get k9s plugin directory from k9s info")
+	return "", fmt.Errorf("failed to get %s from k9s info", field)
 }
 
 func fileExists(path string) bool {
@@ -1,6 +1,10 @@
 package k9s
 
-import "testing"
+import (
+	"testing"
+
+	xrefs "github.com/nkzk/xrefs/internal/config"
+)
 
 func TestGetPluginDirectory(t *testing.T) {
 	tests := []struct {
@@ -71,3 +75,35 @@ ScreenDumps:       /home/test/.k9s/screen-dumps
 		})
 	}
 }
+
+func TestSkinToTheme(t *testing.T) {
+	input := `
+k9s:
+  body:
+    fgColor: "#f8f8f2"
+  frame:
+    status:
+      errorColor: "#ff5555"
+      killColor: red
+  views:
+    table:
+      cursorFgColor: "#f8f8f2"
+      cursorBgColor: "#44475a"
+`
+	base := xrefs.DarkTheme
+
+	got, err := skinToTheme([]byte(input), base)
+	if err != nil {
+		t.Fatalf("%v", err)
+	}
+
+	if got.Foreground != "#f8f8f2" {
+		t.Errorf("expected the body colour as foreground, got %s", got.Foreground)
+	}
+	if got.Error != "#ff5555" || got.Selected != "#f8f8f2" || got.SelectedBackground != "#44475a" {
+		t.Errorf("expected skin colours, got error %s selected %s on %s", got.Error, got.Selected, got.SelectedBackground)
+	}
+	if got.NotFound != base.NotFound {
+		t.Errorf("expected named colours to keep the base colour, got %s", got.NotFound)
+	}
+}
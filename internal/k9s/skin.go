@@ -0,0 +1,139 @@
+package k9s
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	xrefs "github.com/nkzk/xrefs/internal/config"
+	"github.com/nkzk/xrefs/internal/utils"
+	"gopkg.in/yaml.v3"
+)
+
+// skin is the part of a k9s skin file that maps onto a theme.
+type skin struct {
+	K9s struct {
+		Body struct {
+			FgColor string `yaml:"fgColor"`
+		} `yaml:"body"`
+		Frame struct {
+			Title struct {
+				FgColor        string `yaml:"fgColor"`
+				HighlightColor string `yaml:"highlightColor"`
+			} `yaml:"title"`
+			Status struct {
+				NewColor       string `yaml:"newColor"`
+				ModifyColor    string `yaml:"modifyColor"`
+				AddColor       string `yaml:"addColor"`
+				PendingColor   string `yaml:"pendingColor"`
+				ErrorColor     string `yaml:"errorColor"`
+				HighlightColor string `yaml:"highlightColor"`
+				KillColor      string `yaml:"killColor"`
+				CompletedColor string `yaml:"completedColor"`
+			} `yaml:"status"`
+		} `yaml:"frame"`
+		Views struct {
+			Table struct {
+				FgColor       string `yaml:"fgColor"`
+				CursorFgColor string `yaml:"cursorFgColor"`
+				CursorBgColor string `yaml:"cursorBgColor"`
+				Header        struct {
+					FgColor string `yaml:"fgColor"`
+				} `yaml:"header"`
+			} `yaml:"table"`
+		} `yaml:"views"`
+	} `yaml:"k9s"`
+}
+
+// SkinTheme returns base with the colours of the active k9s skin, so xrefs
+// looks like the k9s it is launched from. Only hex colours are used, named
+// colours of the skin are left to base.
+func SkinTheme(base xrefs.Theme) (xrefs.Theme, error) {
+	output, err := utils.RunCommand("k9s", "info")
+	if err != nil {
+		return base, fmt.Errorf("failed to get k9s info: %w", err)
+	}
+
+	configPath, err := getInfoPath(output, "Config")
+	if err != nil {
+		return base, err
+	}
+
+	skinsDir, err := getInfoPath(output, "Skins")
+	if err != nil {
+		return base, err
+	}
+
+	name, err := activeSkin(configPath)
+	if err != nil {
+		return base, err
+	}
+	if name == "" {
+		return base, nil // k9s uses its default skin
+	}
+
+	b, err := os.ReadFile(filepath.Join(skinsDir, name+".yaml"))
+	if err != nil {
+		return base, fmt.Errorf("read k9s skin %s: %w", name, err)
+	}
+
+	return skinToTheme(b, base)
+}
+
+// activeSkin returns the skin set in the k9s config file.
+func activeSkin(configPath string) (string, error) {
+	b, err := os.ReadFile(configPath)
+	if err != nil {
+		return "", fmt.Errorf("read %s: %w", configPath, err)
+	}
+
+	var c struct {
+		K9s struct {
+			UI struct {
+				Skin string `yaml:"skin"`
+			} `yaml:"ui"`
+		} `yaml:"k9s"`
+	}
+	if err := yaml.Unmarshal(b, &c); err != nil {
+		return "", fmt.Errorf("parse %s: %w", configPath, err)
+	}
+
+	return c.K9s.UI.Skin, nil
+}
+
+func skinToTheme(b []byte, base xrefs.Theme) (xrefs.Theme, error) {
+	var s skin
+	if err := yaml.Unmarshal(b, &s); err != nil {
+		return base, fmt.Errorf("parse k9s skin: %w", err)
+	}
+
+	k := s.K9s
+	foreground := k.Views.Table.FgColor
+	if foreground == "" {
+		foreground = k.Body.FgColor
+	}
+
+	return base.Merge(xrefs.Theme{
+		Foreground:         hex(foreground),
+		Muted:              hex(k.Views.Table.Header.FgColor),
+		Selected:           hex(k.Views.Table.CursorFgColor),
+		SelectedBackground: hex(k.Views.Table.CursorBgColor),
+		Title:              hex(k.Frame.Title.FgColor),
+		Success:            hex(k.Frame.Status.CompletedColor),
+		Warning:            hex(k.Frame.Status.ModifyColor),
+		Error:              hex(k.Frame.Status.ErrorColor),
+		NotFound:           hex(k.Frame.Status.KillColor),
+		Suspended:          hex(k.Frame.Status.PendingColor),
+		Info:               hex(k.Frame.Status.HighlightColor),
+	}), nil
+}
+
+// hex returns c if it is a hex colour, lipgloss does not know the colour
+// names k9s accepts.
+func hex(c string) string {
+	if strings.HasPrefix(c, "#") {
+		return c
+	}
+	return ""
+}
@@ -0,0 +1,133 @@
+package models
+
+import "fmt"
+
+// Severity classifies the health of a resource, ordered from healthy to worst.
+type Severity int
+
+const (
+	SeverityHealthy Severity = iota
+	// SeverityUnknown resources have not been fetched yet, or report no clear status.
+	SeverityUnknown
+	// SeverityUnhealthy resources report a Ready or Synced condition that is False.
+	SeverityUnhealthy
+	SeverityNotFound
+	SeverityErrored
+)
+
+func (s Severity) String() string {
+	switch s {
+	case SeverityHealthy:
+		return "healthy"
+	case SeverityUnknown:
+		return "unknown"
+	case SeverityUnhealthy:
+		return "unhealthy"
+	case SeverityNotFound:
+		return "not found"
+	case SeverityErrored:
+		return "errored"
+	}
+	return "invalid"
+}
+
+// Severity returns the health of the resource itself, ignoring its children.
+// Resources without conditions, like ConfigMaps, are healthy when they exist.
+func (r Resource) Severity() Severity {
+	switch {
+	case r.Error != nil:
+		return SeverityErrored
+	case r.NotFound:
+		return SeverityNotFound
+	case r.Unstructured == nil:
+		return SeverityUnknown
+	}
+
+	ready := r.Conditions.Get("Ready")
+	synced := r.Conditions.Get("Synced")
+
+	switch {
+	case ready.Status == "False", synced.Status == "False":
+		return SeverityUnhealthy
+	case ready.Status == "True":
+		return SeverityHealthy
+	case ready.Status == "Unknown":
+		return SeverityUnknown
+	case len(r.Conditions) == 0:
+		return SeverityHealthy
+	}
+
+	return SeverityUnknown
+}
+
+// FailingCondition returns the condition explaining why the resource is
+// unhealthy: Ready if it is not True, otherwise the first condition that is False.
+func (r Resource) FailingCondition() (Condition, bool) {
+	if ready := r.Conditions.Get("Ready"); ready.Status == "False" || ready.Status == "Unknown" {
+		return ready, true
+	}
+
+	if synced := r.Conditions.Get("Synced"); synced.Status == "False" {
+		return synced, true
+	}
+
+	for _, c := range r.Conditions {
+		if c.Status == "False" {
+			return c, true
+		}
+	}
+
+	return Condition{}, false
+}
+
+// Rollup summarizes the health of the descendants of a resource.
+type Rollup struct {
+	Ready int // descendants that are healthy
+	Total int // descendants that were loaded
+
+	// NotLoaded counts the descendants that were never fetched, like the
+	// children of a collapsed parent. They are left out of Total and Worst.
+	NotLoaded int
+
+	// Worst is the worst severity among the descendants.
+	Worst Severity
+}
+
+// Rollup computes the health of all loaded descendants of the resource, so
+// a collapsed parent can show that something below it is failing.
+func (r Resource) Rollup() Rollup {
+	var out Rollup
+	for i := range r.Children {
+		r.Children[i].Walk(func(c *Resource) {
+			if !c.Loaded() {
+				out.NotLoaded++
+				return
+			}
+
+			s := c.Severity()
+			out.Total++
+			if s == SeverityHealthy {
+				out.Ready++
+			}
+			out.Worst = max(out.Worst, s)
+		})
+	}
+	return out
+}
+
+// String renders the rollup like `12/14 ready`, or `3/3 ready, 11 not
+// loaded` when some descendants were not fetched.
+func (r Rollup) String() string {
+	switch {
+	case r.NotLoaded == 0:
+		return fmt.Sprintf("%d/%d ready", r.Ready, r.Total)
+	case r.Total == 0:
+		return fmt.Sprintf("%d not loaded", r.NotLoaded)
+	}
+	return fmt.Sprintf("%d/%d ready, %d not loaded", r.Ready, r.Total, r.NotLoaded)
+}
+
+// Loaded reports whether the resource was fetched, found or not.
+func (r Resource) Loaded() bool {
+	return r.Unstructured != nil || r.NotFound || r.Error != nil
+}
@@ -1,6 +1,8 @@
 package models
 
 import (
+	"time"
+
 	"github.com/google/uuid"
 	v1 "k8s.io/api/core/v1"
 	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
@@ -12,6 +14,9 @@ type Resource struct {
 	Ref          *v1.ObjectReference
 	Unstructured *unstructured.Unstructured
 	Conditions   Conditions
+	History      []Revision   // revisions observed during the session, oldest first
+	Transitions  []Transition // condition changes observed during the session, oldest first
+	ChangedAt    time.Time    // when the conditions last changed during the session
 
 	ID       string
 	Parent   *Resource
@@ -40,6 +45,73 @@ func NewResource(
 	}
 }
 
+// MaxHistory is the number of revisions kept per resource.
+const MaxHistory = 10
+
+// Revision is a version of a resource observed during the session.
+type Revision struct {
+	ResourceVersion string
+	ObservedAt      time.Time
+	Object          *unstructured.Unstructured
+}
+
+// Record appends u to the history of the resource unless its resourceVersion
+// was already recorded, dropping the oldest revision when the history is full.
+func (r *Resource) Record(u *unstructured.Unstructured) {
+	if u == nil {
+		return
+	}
+
+	if n := len(r.History); n > 0 && r.History[n-1].ResourceVersion == u.GetResourceVersion() {
+		return
+	}
+
+	history := append([]Revision(nil), r.History...)
+	history = append(history, Revision{
+		ResourceVersion: u.GetResourceVersion(),
+		ObservedAt:      time.Now(),
+		Object:          u,
+	})
+
+	if len(history) > MaxHistory {
+		history = history[len(history)-MaxHistory:]
+	}
+
+	r.History = history
+}
+
+// Walk calls fn for r and all of its descendants, parents before children.
+func (r *Resource) Walk(fn func(*Resource)) {
+	fn(r)
+	for i := range r.Children {
+		r.Children[i].Walk(fn)
+	}
+}
+
+// Find returns the node of the tree with the given ID, nil when there is none.
+func (r *Resource) Find(id string) *Resource {
+	if r.ID == id {
+		return r
+	}
+	for i := range r.Children {
+		if found := r.Children[i].Find(id); found != nil {
+			return found
+		}
+	}
+	return nil
+}
+
+// Remaining counts the resources in the tree that still exist.
+func (r *Resource) Remaining() int {
+	n := 0
+	r.Walk(func(r *Resource) {
+		if !r.NotFound {
+			n++
+		}
+	})
+	return n
+}
+
 // implement tea list item interface
 
 func (r Resource) Title() string       { return r.Unstructured.GetName() }
@@ -55,6 +127,7 @@ type Condition struct {
 	Status             string `json:"status"`
 	ConditionType      string `json:"type"`
 	Reason             string `json:"reason"`
+	Message            string `json:"message"`
 	LastTransitionTime string `json:"lastTransitionTime"`
 }
 
@@ -0,0 +1,60 @@
+package models
+
+import "time"
+
+// MaxTransitions is the number of condition transitions kept per resource.
+const MaxTransitions = 50
+
+// Transition is a change of a condition observed during the session.
+type Transition struct {
+	At      time.Time
+	Type    string
+	From    string // status before the change, empty for a new condition
+	To      string // status after the change, empty for a removed condition
+	Reason  string
+	Message string
+}
+
+// RecordTransitions records how conditions differ from the current conditions
+// of the resource, and marks the resource as changed at when they do.
+func (r *Resource) RecordTransitions(conditions Conditions, at time.Time) {
+	var transitions []Transition
+
+	for _, c := range conditions {
+		old := r.Conditions.Get(c.ConditionType)
+		if old.Status == c.Status && old.Reason == c.Reason {
+			continue
+		}
+
+		transitions = append(transitions, Transition{
+			At:      at,
+			Type:    c.ConditionType,
+			From:    old.Status,
+			To:      c.Status,
+			Reason:  c.Reason,
+			Message: c.Message,
+		})
+	}
+
+	for _, old := range r.Conditions {
+		if conditions.Get(old.ConditionType).ConditionType == "" {
+			transitions = append(transitions, Transition{
+				At:   at,
+				Type: old.ConditionType,
+				From: old.Status,
+			})
+		}
+	}
+
+	if len(transitions) == 0 {
+		return
+	}
+
+	timeline := append(append([]Transition(nil), r.Transitions...), transitions...)
+	if len(timeline) > MaxTransitions {
+		timeline = timeline[len(timeline)-MaxTransitions:]
+	}
+
+	r.Transitions = timeline
+	r.ChangedAt = at
+}
@@ -0,0 +1,103 @@
+package models
+
+import (
+	"fmt"
+	"testing"
+	"time"
+)
+
+func TestRecordTransitions(t *testing.T) {
+	at := time.Date(2026, 1, 1, 12, 0, 0, 0, time.UTC)
+
+	ready := Condition{ConditionType: "Ready", Status: "False", Reason: "Creating"}
+	synced := Condition{ConditionType: "Synced", Status: "True", Reason: "ReconcileSuccess"}
+
+	tests := []struct {
+		name    string
+		old     Conditions
+		new     Conditions
+		want    []Transition
+		changed bool
+	}{
+		{
+			name: "unchanged",
+			old:  Conditions{ready, synced},
+			new:  Conditions{ready, synced},
+		},
+		{
+			name:    "added",
+			old:     Conditions{synced},
+			new:     Conditions{synced, ready},
+			want:    []Transition{{At: at, Type: "Ready", To: "False", Reason: "Creating"}},
+			changed: true,
+		},
+		{
+			name: "status changed",
+			old:  Conditions{ready},
+			new:  Conditions{{ConditionType: "Ready", Status: "True", Reason: "Available", Message: "up"}},
+			want: []Transition{
+				{At: at, Type: "Ready", From: "False", To: "True", Reason: "Available", Message: "up"},
+			},
+			changed: true,
+		},
+		{
+			name:    "reason changed",
+			old:     Conditions{ready},
+			new:     Conditions{{ConditionType: "Ready", Status: "False", Reason: "Unavailable"}},
+			want:    []Transition{{At: at, Type: "Ready", From: "False", To: "False", Reason: "Unavailable"}},
+			changed: true,
+		},
+		{
+			name: "message changed",
+			old:  Conditions{ready},
+			new:  Conditions{{ConditionType: "Ready", Status: "False", Reason: "Creating", Message: "waiting"}},
+		},
+		{
+			name:    "removed",
+			old:     Conditions{ready, synced},
+			new:     Conditions{ready},
+			want:    []Transition{{At: at, Type: "Synced", From: "True"}},
+			changed: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := Resource{Conditions: tt.old}
+			r.RecordTransitions(tt.new, at)
+
+			if len(r.Transitions) != len(tt.want) {
+				t.Fatalf("expected %d transitions, got %+v", len(tt.want), r.Transitions)
+			}
+			for i := range tt.want {
+				if r.Transitions[i] != tt.want[i] {
+					t.Errorf("expected %+v, got %+v", tt.want[i], r.Transitions[i])
+				}
+			}
+			if got := !r.ChangedAt.IsZero(); got != tt.changed {
+				t.Errorf("expected changed %v, got %v", tt.changed, got)
+			}
+		})
+	}
+}
+
+func TestRecordTransitionsKeepsTheLatest(t *testing.T) {
+	at := time.Date(2026, 1, 1, 12, 0, 0, 0, time.UTC)
+
+	var r Resource
+	for i := range MaxTransitions + 5 {
+		c := Conditions{{ConditionType: "Ready", Status: "False", Reason: fmt.Sprint(i)}}
+		r.RecordTransitions(c, at.Add(time.Duration(i)*time.Second))
+		r.Conditions = c
+	}
+
+	if len(r.Transitions) != MaxTransitions {
+		t.Fatalf("expected %d transitions, got %d", MaxTransitions, len(r.Transitions))
+	}
+	if first := r.Transitions[0].Reason; first != "5" {
+		t.Errorf("expected the oldest transitions to be dropped, first is %s", first)
+	}
+	if last := r.Transitions[MaxTransitions-1].Reason; last != fmt.Sprint(MaxTransitions+4) {
+		t.Errorf("expected the latest transition last, got %s", last)
+	}
+}
@@ -0,0 +1,485 @@
+package ui
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"strings"
+	"time"
+
+	"charm.land/bubbles/v2/key"
+	"charm.land/bubbles/v2/textinput"
+	tea "charm.land/bubbletea/v2"
+	"github.com/nkzk/xrefs/internal/diagnose"
+	"github.com/nkzk/xrefs/internal/k8s"
+	"github.com/nkzk/xrefs/internal/models"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	utilyaml "k8s.io/apimachinery/pkg/util/yaml"
+)
+
+// actionTimeout bounds the api calls made by actions triggered from the TUI.
+const actionTimeout = 30 * time.Second
+
+type (
+	// editFinishedMsg is sent when the editor started by editResource exits.
+	editFinishedMsg struct {
+		path     string
+		original []byte
+		ref      *corev1.ObjectReference // the edited object, which the edit may not change
+		err      error
+	}
+
+	// actionResultMsg reports the outcome of an action against the cluster.
+	actionResultMsg struct {
+		message string
+		err     error
+	}
+
+	// subtreeLoadedMsg carries the entirely loaded copy of a subtree, for an
+	// action or a report that covers all of its resources.
+	subtreeLoadedMsg struct {
+		tree *models.Resource
+		then func(m *Model, tree *models.Resource) tea.Cmd
+		err  error
+	}
+)
+
+// confirmation is an action waiting for the user to confirm it with y.
+type confirmation struct {
+	prompt string
+	action tea.Cmd
+}
+
+// reconcile asks to confirm a reconcile request for r, or for r and its
+// descendants when subtree is set. Flux resources get the Flux reconcile
+// annotation, Crossplane resources have an annotation bumped.
+func (m Model) reconcile(r *models.Resource, subtree bool) (*confirmation, tea.Cmd) {
+	now := time.Now()
+	patchFor := func(r *models.Resource) []byte {
+		switch {
+		case k8s.IsFluxResource(r.Unstructured):
+			return k8s.FluxReconcilePatch(now)
+		case k8s.IsCrossplaneResource(r.Unstructured):
+			return k8s.CrossplaneReconcilePatch(now)
+		}
+		return nil
+	}
+
+	targets := actionTargets(r, subtree, patchFor)
+	if len(targets) == 0 {
+		return nil, actionResult("", fmt.Errorf("reconcile is only supported for Flux and Crossplane resources"))
+	}
+
+	return &confirmation{
+		prompt: fmt.Sprintf("Reconcile %s?", targetsName(r, targets)),
+		action: m.patchResources(targets, patchFor, "requested reconcile of "+targetsName(r, targets)),
+	}, nil
+}
+
+// fluxToggleSuspend asks to confirm suspending or resuming a Flux resource.
+func (m Model) fluxToggleSuspend(r *models.Resource) (*confirmation, tea.Cmd) {
+	if r == nil || !k8s.IsFluxResource(r.Unstructured) {
+		return nil, actionResult("", fmt.Errorf("suspend is only supported for Flux resources"))
+	}
+
+	suspend := !k8s.FluxSuspended(r.Unstructured)
+	patch := k8s.FluxSuspendPatch(suspend, time.Now())
+	patchFor := func(*models.Resource) []byte { return patch }
+
+	verb, done := "Suspend", "suspended"
+	if !suspend {
+		verb, done = "Resume", "resumed"
+	}
+
+	return &confirmation{
+		prompt: fmt.Sprintf("%s %s?", verb, displayName(r)),
+		action: m.patchResources([]*models.Resource{r}, patchFor, done+" "+displayName(r)),
+	}, nil
+}
+
+// crossplaneTogglePause asks to confirm pausing or unpausing r, or r and its
+// descendants when subtree is set. The state of r decides the direction.
+func (m Model) crossplaneTogglePause(r *models.Resource, subtree bool) (*confirmation, tea.Cmd) {
+	if r == nil || !k8s.IsCrossplaneResource(r.Unstructured) {
+		return nil, actionResult("", fmt.Errorf("pause is only supported for Crossplane resources"))
+	}
+
+	pause := !k8s.CrossplanePaused(r.Unstructured)
+	patch := k8s.CrossplanePausePatch(pause)
+	patchFor := func(r *models.Resource) []byte {
+		if k8s.IsCrossplaneResource(r.Unstructured) {
+			return patch
+		}
+		return nil
+	}
+
+	verb, done := "Pause", "paused"
+	if !pause {
+		verb, done = "Unpause", "unpaused"
+	}
+
+	targets := actionTargets(r, subtree, patchFor)
+
+	return &confirmation{
+		prompt: fmt.Sprintf("%s %s?", verb, targetsName(r, targets)),
+		action: m.patchResources(targets, patchFor, done+" "+targetsName(r, targets)),
+	}, nil
+}
+
+// loadSubtree loads a copy of the whole subtree of r before then acts on it
+// or reports on it, so resources below collapsed nodes are not skipped. The
+// copy leaves the tree of the producer alone.
+func (m Model) loadSubtree(r *models.Resource, then func(m *Model, tree *models.Resource) tea.Cmd) tea.Cmd {
+	if r == nil || r.Unstructured == nil {
+		return func() tea.Msg {
+			return subtreeLoadedMsg{tree: r, then: then}
+		}
+	}
+
+	if m.client == nil {
+		return actionResult("", fmt.Errorf("no client configured"))
+	}
+
+	tree := models.NewResource(nil, r.Unstructured, r.Ref)
+	tree.ID = r.ID
+	tree.Expanded = true
+
+	kClient := m.client
+	return func() tea.Msg {
+		ctx, cancel := context.WithTimeout(context.Background(), actionTimeout)
+		defer cancel()
+
+		if err := k8s.LoadTree(ctx, tree, kClient, -1); err != nil {
+			return subtreeLoadedMsg{err: fmt.Errorf("cannot load the subtree of %s: %w", displayName(r), err)}
+		}
+
+		return subtreeLoadedMsg{tree: tree, then: then}
+	}
+}
+
+// findObject returns the first node of tree referring to the object of ref,
+// to find a node of the tree shown in a loaded copy of it.
+func findObject(tree *models.Resource, ref *corev1.ObjectReference) *models.Resource {
+	var found *models.Resource
+	tree.Walk(func(r *models.Resource) {
+		if found == nil && k8s.SameObject(r.Ref, ref) {
+			found = r
+		}
+	})
+	return found
+}
+
+// actionTargets returns r, or r and its descendants, that exist and have a patch.
+func actionTargets(r *models.Resource, subtree bool, patchFor func(*models.Resource) []byte) []*models.Resource {
+	if r == nil {
+		return nil
+	}
+
+	var targets []*models.Resource
+	add := func(r *models.Resource) {
+		if !r.NotFound && r.Unstructured != nil && patchFor(r) != nil {
+			targets = append(targets, r)
+		}
+	}
+
+	if !subtree {
+		add(r)
+		return targets
+	}
+
+	r.Walk(add)
+	return targets
+}
+
+// targetsName describes the targets of an action started from r.
+func targetsName(r *models.Resource, targets []*models.Resource) string {
+	if len(targets) == 1 && targets[0] == r {
+		return displayName(r)
+	}
+	return fmt.Sprintf("%d resources in the subtree of %s", len(targets), displayName(r))
+}
+
+// patchResources returns a command applying a merge patch to each target.
+func (m Model) patchResources(targets []*models.Resource, patchFor func(*models.Resource) []byte, done string) tea.Cmd {
+	type job struct {
+		ref   *corev1.ObjectReference
+		name  string
+		patch []byte
+	}
+
+	// resolve everything up front, the tree is mutated by the producer
+	jobs := make([]job, 0, len(targets))
+	for _, t := range targets {
+		jobs = append(jobs, job{ref: t.Ref, name: displayName(t), patch: patchFor(t)})
+	}
+
+	kClient := m.client
+
+	return func() tea.Msg {
+		if kClient == nil {
+			return actionResultMsg{err: fmt.Errorf("no client configured")}
+		}
+
+		ctx, cancel := context.WithTimeout(context.Background(), actionTimeout)
+		defer cancel()
+
+		var errs []error
+		for _, j := range jobs {
+			if err := kClient.MergePatch(ctx, j.ref, j.patch); err != nil {
+				errs = append(errs, fmt.Errorf("patch %s: %w", j.name, err))
+			}
+		}
+
+		if len(errs) > 0 {
+			return actionResultMsg{err: errors.Join(errs...)}
+		}
+
+		return actionResultMsg{message: done}
+	}
+}
+
+// displayName returns kind/name of the resource.
+func displayName(r *models.Resource) string {
+	if r.Unstructured != nil && r.Unstructured.GetKind() != "" {
+		return r.Unstructured.GetKind() + "/" + r.Unstructured.GetName()
+	}
+	return r.Ref.Kind + "/" + r.Ref.Name
+}
+
+// editResource writes the resource to a temporary file, stripped of status
+// and managedFields, and opens it in $EDITOR while the TUI is suspended.
+func editResource(r *models.Resource) tea.Cmd {
+	if r == nil || r.Unstructured == nil || r.NotFound {
+		return actionResult("", fmt.Errorf("nothing to edit"))
+	}
+	if k8s.IsSelection(r) {
+		return actionResult("", fmt.Errorf("a selection cannot be edited, edit the objects it lists"))
+	}
+
+	obj := r.Unstructured.DeepCopy()
+	unstructured.RemoveNestedField(obj.Object, "status")
+	unstructured.RemoveNestedField(obj.Object, "metadata", "managedFields")
+
+	y, err := toYAML(obj)
+	if err != nil {
+		return actionResult("", fmt.Errorf("cannot render yaml: %w", err))
+	}
+
+	f, err := os.CreateTemp("", fmt.Sprintf("xrefs-%s-*.yaml", strings.ToLower(obj.GetKind())))
+	if err != nil {
+		return actionResult("", fmt.Errorf("cannot create temporary file: %w", err))
+	}
+	defer f.Close()
+
+	if _, err := f.WriteString(y); err != nil {
+		return actionResult("", fmt.Errorf("cannot write temporary file: %w", err))
+	}
+
+	editor := strings.Fields(os.Getenv("EDITOR"))
+	if len(editor) == 0 {
+		editor = []string{"vi"}
+	}
+
+	cmd := exec.Command(editor[0], append(editor[1:], f.Name())...) // #nosec G204 -- the editor is chosen by the user
+
+	return tea.ExecProcess(cmd, func(err error) tea.Msg {
+		return editFinishedMsg{
+			path:     f.Name(),
+			original: []byte(y),
+			ref: &corev1.ObjectReference{
+				APIVersion: obj.GetAPIVersion(),
+				Kind:       obj.GetKind(),
+				Name:       obj.GetName(),
+				Namespace:  obj.GetNamespace(),
+			},
+			err: err,
+		}
+	})
+}
+
+// applyEdit reads the edited file and applies it with server-side apply.
+func (m Model) applyEdit(msg editFinishedMsg) tea.Cmd {
+	defer os.Remove(msg.path)
+
+	if msg.err != nil {
+		return actionResult("", fmt.Errorf("editor failed: %w", msg.err))
+	}
+
+	edited, err := os.ReadFile(msg.path)
+	if err != nil {
+		return actionResult("", fmt.Errorf("cannot read edited file: %w", err))
+	}
+
+	if bytes.Equal(bytes.TrimSpace(edited), bytes.TrimSpace(msg.original)) {
+		return actionResult("edit cancelled, no changes made", nil)
+	}
+
+	obj := &unstructured.Unstructured{}
+	if err := utilyaml.Unmarshal(edited, &obj.Object); err != nil {
+		return actionResult("", fmt.Errorf("invalid yaml: %w", err))
+	}
+
+	if len(obj.Object) == 0 {
+		return actionResult("", fmt.Errorf("the edited file is empty, nothing applied"))
+	}
+
+	// applying an object of another name would create it rather than edit
+	if ref := msg.ref; obj.GetAPIVersion() != ref.APIVersion || obj.GetKind() != ref.Kind ||
+		obj.GetName() != ref.Name || obj.GetNamespace() != ref.Namespace {
+		return actionResult("", fmt.Errorf(
+			"the apiVersion, kind, name and namespace of %s/%s cannot be changed, nothing applied",
+			ref.Kind, ref.Name,
+		))
+	}
+
+	if m.client == nil {
+		return actionResult("", fmt.Errorf("no client configured"))
+	}
+
+	kClient := m.client
+	return func() tea.Msg {
+		ctx, cancel := context.WithTimeout(context.Background(), actionTimeout)
+		defer cancel()
+
+		if err := kClient.Apply(ctx, obj); err != nil {
+			return actionResultMsg{err: fmt.Errorf("apply %s/%s: %w", obj.GetKind(), obj.GetName(), err)}
+		}
+
+		return actionResultMsg{message: fmt.Sprintf("applied %s/%s", obj.GetKind(), obj.GetName())}
+	}
+}
+
+func actionResult(message string, err error) tea.Cmd {
+	return func() tea.Msg {
+		return actionResultMsg{message: message, err: err}
+	}
+}
+
+// pendingDeletion is a deletion waiting for confirmation. Deletions that take
+// descendants with them have to be confirmed by typing the resource name.
+type pendingDeletion struct {
+	name   string
+	typed  bool
+	input  textinput.Model
+	action tea.Cmd
+}
+
+// deletable returns an error when r cannot be deleted.
+func deletable(r *models.Resource) error {
+	if r == nil || r.NotFound || r.Unstructured == nil {
+		return fmt.Errorf("nothing to delete")
+	}
+	if k8s.IsSelection(r) {
+		return fmt.Errorf("a selection cannot be deleted, delete the objects it lists")
+	}
+	return nil
+}
+
+// planDeletion computes the deletion plan of r in the tree under root, to be
+// shown before asking for confirmation.
+func (m Model) planDeletion(root, r *models.Resource) (diagnose.DeletionPlan, *pendingDeletion, tea.Cmd) {
+	if err := deletable(r); err != nil {
+		return diagnose.DeletionPlan{}, nil, actionResult("", err)
+	}
+
+	plan := diagnose.PlanDeletion(root, r)
+
+	d := &pendingDeletion{
+		name:   r.Unstructured.GetName(),
+		typed:  plan.RequiresTypedConfirmation(),
+		action: m.deleteResource(r),
+	}
+
+	var cmd tea.Cmd
+	if d.typed {
+		d.input = textinput.New()
+		d.input.Prompt = "> "
+		d.input.Placeholder = d.name
+		cmd = d.input.Focus()
+	}
+
+	return plan, d, cmd
+}
+
+// updateDeletion handles key presses while a deletion waits for confirmation.
+func (m Model) updateDeletion(msg tea.KeyPressMsg) (Model, tea.Cmd) {
+	d := m.deletion
+
+	cancel := func() (Model, tea.Cmd) {
+		m.deletion = nil
+		m.showPanel = false
+		return m, actionResult("deletion cancelled", nil)
+	}
+
+	if key.Matches(msg, m.keys.Quit) {
+		return m, tea.Quit
+	}
+
+	switch msg.String() {
+	case "esc":
+		return cancel()
+	case "up", "down", "pgup", "pgdown":
+		var cmd tea.Cmd
+		m.panel, cmd = m.panel.Update(msg)
+		return m, cmd
+	}
+
+	if !d.typed {
+		if msg.String() != "y" {
+			return cancel()
+		}
+		m.deletion = nil
+		m.showPanel = false
+		return m, d.action
+	}
+
+	if msg.String() == "enter" {
+		if strings.TrimSpace(d.input.Value()) != d.name {
+			d.input.SetValue("")
+			d.input.Placeholder = "name did not match, type " + d.name
+			return m, nil
+		}
+		m.deletion = nil
+		m.showPanel = false
+		return m, d.action
+	}
+
+	var cmd tea.Cmd
+	d.input, cmd = d.input.Update(msg)
+	return m, cmd
+}
+
+// prompt renders the confirmation prompt of a pending deletion.
+func (d pendingDeletion) prompt() string {
+	if d.typed {
+		return confirmStyle.Render("Type the name of the resource to delete it with its descendants, esc to cancel") +
+			"\n" + d.input.View()
+	}
+	return confirmStyle.Render(fmt.Sprintf("Delete %s? (y/N)", d.name))
+}
+
+// deleteResource returns a command deleting r.
+func (m Model) deleteResource(r *models.Resource) tea.Cmd {
+	kClient := m.client
+	ref := r.Ref
+	name := displayName(r)
+
+	return func() tea.Msg {
+		if kClient == nil {
+			return actionResultMsg{err: fmt.Errorf("no client configured")}
+		}
+
+		ctx, cancel := context.WithTimeout(context.Background(), actionTimeout)
+		defer cancel()
+
+		if err := kClient.Delete(ctx, ref); err != nil {
+			return actionResultMsg{err: fmt.Errorf("delete %s: %w", name, err)}
+		}
+
+		return actionResultMsg{message: "requested deletion of " + name}
+	}
+}
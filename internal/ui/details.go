@@ -0,0 +1,204 @@
+package ui
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"charm.land/bubbles/v2/viewport"
+	tea "charm.land/bubbletea/v2"
+	"github.com/nkzk/xrefs/internal/k8s"
+	"github.com/nkzk/xrefs/internal/models"
+	corev1 "k8s.io/api/core/v1"
+)
+
+// detailsModel shows the conditions, events and YAML of the selected
+// resource next to the tree in the split view, following the cursor.
+type detailsModel struct {
+	viewport viewport.Model
+
+	resource *models.Resource
+	events   []corev1.Event
+	loaded   bool
+	err      error
+
+	// seq identifies the latest selection, so events loaded for a resource
+	// the cursor already moved away from are dropped.
+	seq int
+}
+
+type (
+	// loadEventsMsg loads the events of the selection seq once the cursor
+	// has rested on it.
+	loadEventsMsg struct {
+		seq int
+	}
+
+	eventsMsg struct {
+		seq    int
+		events []corev1.Event
+		err    error
+	}
+)
+
+func newDetailsModel() detailsModel {
+	vp := viewport.New()
+	vp.SoftWrap = true
+	return detailsModel{
+		viewport: vp,
+	}
+}
+
+// SetResource shows r, scrolling to the top and reloading its events when
+// the selection changed. Otherwise r is re-rendered in place.
+func (m *detailsModel) SetResource(r *models.Resource) tea.Cmd {
+	if r == m.resource {
+		return nil
+	}
+	if r != nil && m.resource != nil && r.ID == m.resource.ID {
+		m.resource = r
+		m.render()
+		return nil
+	}
+
+	m.resource = r
+	m.events, m.loaded, m.err = nil, false, nil
+	m.seq++
+	m.render()
+	m.viewport.GotoTop()
+
+	seq := m.seq
+	return tea.Tick(150*time.Millisecond, func(time.Time) tea.Msg {
+		return loadEventsMsg{seq: seq}
+	})
+}
+
+// Refresh re-renders the resource after the producer updated the tree, and
+// reloads its events.
+func (m *detailsModel) Refresh(r *models.Resource) tea.Cmd {
+	if r == nil || m.resource == nil || r.ID != m.resource.ID {
+		return m.SetResource(r)
+	}
+
+	m.resource = r
+	m.render()
+	m.seq++
+
+	seq := m.seq
+	return func() tea.Msg { return loadEventsMsg{seq: seq} }
+}
+
+// loadEvents lists the events of the shown resource.
+func (m detailsModel) loadEvents(client k8s.Client, seq int) tea.Cmd {
+	r := m.resource
+	if seq != m.seq || r == nil || r.Ref == nil || r.NotFound || client == nil {
+		return nil
+	}
+
+	ref := *r.Ref
+	if r.Unstructured != nil {
+		ref.UID = r.Unstructured.GetUID()
+	}
+	return func() tea.Msg {
+		ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+		defer cancel()
+
+		events, err := client.Events(ctx, &ref)
+		return eventsMsg{seq: seq, events: events, err: err}
+	}
+}
+
+// setEvents shows the loaded events if they belong to the current selection.
+func (m *detailsModel) setEvents(msg eventsMsg) {
+	if msg.seq != m.seq {
+		return
+	}
+
+	m.events, m.err, m.loaded = msg.events, msg.err, true
+
+	offset := m.viewport.YOffset()
+	m.render()
+	m.viewport.SetYOffset(offset)
+}
+
+func (m *detailsModel) render() {
+	r := m.resource
+	if r == nil {
+		m.viewport.SetContent(mutedStyle.Render("nothing selected"))
+		return
+	}
+
+	var b strings.Builder
+
+	b.WriteString(panelTitleStyle.Render(displayName(r)) + "\n")
+	if r.Ref != nil && r.Ref.Namespace != "" {
+		b.WriteString(mutedStyle.Render("namespace: "+r.Ref.Namespace) + "\n")
+	}
+	if r.NotFound {
+		b.WriteString(errorStyle.Render("not found") + "\n")
+		m.viewport.SetContent(b.String())
+		return
+	}
+
+	b.WriteString("\n" + panelTitleStyle.Render("Conditions") + "\n")
+	if len(r.Conditions) == 0 {
+		b.WriteString(mutedStyle.Render("  none") + "\n")
+	}
+	for _, c := range r.Conditions {
+		status := conditionStatus(c.Status) + strings.Repeat(" ", max(7-len(c.Status), 0))
+		line := fmt.Sprintf("  %-14s %s %s", c.ConditionType, status, c.Reason)
+		if t, err := time.Parse(time.RFC3339, c.LastTransitionTime); err == nil {
+			line += mutedStyle.Render(fmt.Sprintf(" (%s ago)", since(t)))
+		}
+		b.WriteString(line + "\n")
+		if c.Message != "" {
+			b.WriteString(mutedStyle.Render("    "+c.Message) + "\n")
+		}
+	}
+
+	if len(r.Transitions) > 0 {
+		b.WriteString("\n" + panelTitleStyle.Render("Changes") + "\n")
+		b.WriteString(timelineReport(r))
+	}
+
+	b.WriteString("\n" + panelTitleStyle.Render("Events") + "\n")
+	switch {
+	case m.err != nil:
+		b.WriteString(errorStyle.Render(fmt.Sprintf("  cannot list events: %v", m.err)) + "\n")
+	case !m.loaded:
+		b.WriteString(mutedStyle.Render("  loading…") + "\n")
+	case len(m.events) == 0:
+		b.WriteString(mutedStyle.Render("  none") + "\n")
+	}
+	for _, e := range m.events {
+		reason := e.Reason
+		if e.Type == corev1.EventTypeWarning {
+			reason = errorStyle.Render(reason)
+		}
+
+		line := fmt.Sprintf("  %s ago %s", since(k8s.EventTime(e)), reason)
+		if e.Count > 1 {
+			line += mutedStyle.Render(fmt.Sprintf(" (x%d)", e.Count))
+		}
+		b.WriteString(line + "\n")
+		b.WriteString(mutedStyle.Render("    "+e.Message) + "\n")
+	}
+
+	b.WriteString("\n" + panelTitleStyle.Render("YAML") + "\n")
+	y, err := toYAML(r.Unstructured)
+	if err != nil {
+		b.WriteString(fmt.Sprintf("error rendering yaml: %v", err))
+	} else {
+		b.WriteString(highlightYAML(y))
+	}
+
+	m.viewport.SetContent(b.String())
+}
+
+// View renders the pane at the given size.
+func (m detailsModel) View(width, height int) string {
+	m.viewport.SetWidth(width)
+	m.viewport.SetHeight(height)
+	return m.viewport.View()
+}
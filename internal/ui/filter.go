@@ -0,0 +1,95 @@
+package ui
+
+import (
+	"charm.land/bubbles/v2/list"
+	"github.com/nkzk/xrefs/internal/models"
+)
+
+// HealthFilter limits the tree to nodes in a given health state, along with
+// their ancestors.
+type HealthFilter string
+
+const (
+	NoHealthFilter  HealthFilter = ""
+	FailingFilter   HealthFilter = "failing" // anything that is not healthy
+	UnhealthyFilter HealthFilter = "unhealthy"
+	NotFoundFilter  HealthFilter = "not found"
+	ErroredFilter   HealthFilter = "errored"
+	UnknownFilter   HealthFilter = "unknown"
+)
+
+// healthFilters is the order the filters are cycled through.
+var healthFilters = []HealthFilter{
+	NoHealthFilter,
+	FailingFilter,
+	UnhealthyFilter,
+	NotFoundFilter,
+	ErroredFilter,
+	UnknownFilter,
+}
+
+// next returns the filter after f in the cycle.
+func (f HealthFilter) next() HealthFilter {
+	for i, h := range healthFilters {
+		if h == f {
+			return healthFilters[(i+1)%len(healthFilters)]
+		}
+	}
+	return NoHealthFilter
+}
+
+// matches reports whether r passes the filter. Resources that were never
+// fetched, like the children of a collapsed node, only pass without a filter.
+func (f HealthFilter) matches(r *models.Resource) bool {
+	if f == NoHealthFilter {
+		return true
+	}
+	if !r.Loaded() {
+		return false
+	}
+
+	s := r.Severity()
+	switch f {
+	case FailingFilter:
+		return s != models.SeverityHealthy
+	case UnhealthyFilter:
+		return s == models.SeverityUnhealthy
+	case NotFoundFilter:
+		return s == models.SeverityNotFound
+	case ErroredFilter:
+		return s == models.SeverityErrored
+	case UnknownFilter:
+		return s == models.SeverityUnknown
+	}
+	return true
+}
+
+// flattenFiltered flattens the nodes matching the filter and their ancestors,
+// regardless of which nodes are expanded, so every match is visible with the
+// path leading to it.
+func flattenFiltered(r models.Resource, f HealthFilter) []list.Item {
+	if !subtreeMatches(&r, f) {
+		return nil
+	}
+
+	return flattenWithPrefix(r, 0, true, "", func(r models.Resource) []models.Resource {
+		var visible []models.Resource
+		for i := range r.Children {
+			if subtreeMatches(&r.Children[i], f) {
+				visible = append(visible, r.Children[i])
+			}
+		}
+		return visible
+	})
+}
+
+// subtreeMatches reports whether r or any of its descendants match the filter.
+func subtreeMatches(r *models.Resource, f HealthFilter) bool {
+	found := false
+	r.Walk(func(c *models.Resource) {
+		if !found && f.matches(c) {
+			found = true
+		}
+	})
+	return found
+}
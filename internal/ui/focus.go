@@ -0,0 +1,100 @@
+package ui
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+
+	tea "charm.land/bubbletea/v2"
+	"github.com/nkzk/xrefs/internal/models"
+)
+
+// crumb is a root that was focused away from, and the row selected in it.
+type crumb struct {
+	root  *models.Resource
+	index int
+}
+
+// focus makes r the root of the tree, keeping the current root on the
+// breadcrumb stack so it can be returned to.
+func (m *Model) focus(r *models.Resource) tea.Cmd {
+	if m.watch == nil {
+		return actionResult("", fmt.Errorf("focus is not available"))
+	}
+
+	if r != nil {
+		// focus on the node of the ownership tree, the usage tree is a snapshot
+		r = m.root.Find(r.ID)
+	}
+	if r == nil || r == m.root {
+		return nil
+	}
+	if r.NotFound || r.Ref == nil {
+		return actionResult("", fmt.Errorf("cannot focus on a resource that was not found"))
+	}
+
+	// keep the ID and what was observed of the node, so the viewer, its
+	// revisions and its timeline carry over to the focused root
+	root := models.NewResource(nil, r.Unstructured, r.Ref)
+	root.ID = r.ID
+	root.Conditions = r.Conditions
+	root.History = slices.Clone(r.History)
+	root.Transitions = slices.Clone(r.Transitions)
+	root.ChangedAt = r.ChangedAt
+	root.Expanded = true
+
+	m.crumbs = append(m.crumbs, crumb{root: m.root, index: m.list.Index()})
+
+	return tea.Batch(m.setRoot(root), m.watchRoot(root))
+}
+
+// unfocus returns to the root that was shown before the last focus.
+func (m *Model) unfocus() tea.Cmd {
+	c := m.crumbs[len(m.crumbs)-1]
+	m.crumbs = m.crumbs[:len(m.crumbs)-1]
+
+	cmd := m.setRoot(c.root)
+	m.list.Select(c.index)
+
+	return tea.Batch(cmd, m.watchRoot(c.root))
+}
+
+// setRoot shows the tree of root, resetting the state tied to the previous one.
+func (m *Model) setRoot(root *models.Resource) tea.Cmd {
+	m.root = root
+	m.usageRoot = nil
+	m.sort = DefaultSort
+	m.search = nil
+	m.delegate.matches = nil
+	m.rootUpdatedAt = time.Time{}
+	m.rootDeletedAt = time.Time{}
+	m.followDeletion = false
+	m.deletionDone = false
+	m.delegate.columns = columnsFor(m.settings, root)
+
+	return m.setItems(m.items(root))
+}
+
+// watchRoot switches the producer to root.
+func (m *Model) watchRoot(root *models.Resource) tea.Cmd {
+	watch := m.watch
+	return func() tea.Msg {
+		if err := watch(root); err != nil {
+			return actionResultMsg{err: err}
+		}
+		return nil
+	}
+}
+
+// breadcrumb renders the path of roots focused on, like `XR/a › XR/b`.
+func (m Model) breadcrumb() string {
+	names := make([]string, 0, len(m.crumbs)+1)
+	for _, c := range m.crumbs {
+		names = append(names, displayName(c.root))
+	}
+
+	return mutedStyle.Render(strings.Join(names, " › ")+" › ") +
+		panelTitleStyle.Render(displayName(m.root)) +
+		mutedStyle.Render(fmt.Sprintf(" (%s back)", m.keys.Back.Help().Key))
+}
@@ -0,0 +1,296 @@
+package ui
+
+import (
+	"fmt"
+	"slices"
+	"sort"
+	"strings"
+
+	"charm.land/bubbles/v2/key"
+	tea "charm.land/bubbletea/v2"
+)
+
+// keyMap holds the key bindings of every view.
+type keyMap struct {
+	Quit    key.Binding
+	Back    key.Binding
+	Clear   key.Binding
+	Help    key.Binding
+	Edit    key.Binding
+	Command key.Binding
+
+	// tree
+	Inspect          key.Binding
+	Toggle           key.Binding
+	Expand           key.Binding
+	Collapse         key.Binding
+	ExpandAll        key.Binding
+	CollapseAll      key.Binding
+	Search           key.Binding
+	NextMatch        key.Binding
+	PrevMatch        key.Binding
+	Filter           key.Binding
+	Usage            key.Binding
+	Reconcile        key.Binding
+	ReconcileSubtree key.Binding
+	Suspend          key.Binding
+	Pause            key.Binding
+	PauseSubtree     key.Binding
+	Delete           key.Binding
+	Stuck            key.Binding
+	Why              key.Binding
+	Focus            key.Binding
+	Split            key.Binding
+	Timeline         key.Binding
+	DetailsDown      key.Binding
+	DetailsUp        key.Binding
+
+	// overview
+	SortWorst key.Binding
+
+	// tabs
+	NextTab key.Binding
+	PrevTab key.Binding
+
+	// viewer
+	Top       key.Binding
+	Bottom    key.Binding
+	Copy      key.Binding
+	Diff      key.Binding
+	FromOlder key.Binding
+	FromNewer key.Binding
+	ToOlder   key.Binding
+	ToNewer   key.Binding
+}
+
+func binding(desc string, keys ...string) key.Binding {
+	return key.NewBinding(key.WithKeys(keys...), key.WithHelp(strings.Join(keys, "/"), desc))
+}
+
+func defaultKeyMap() keyMap {
+	return keyMap{
+		Quit:    binding("quit", "ctrl+c"),
+		Back:    binding("back, or quit from the tree", "q"),
+		Clear:   binding("clear search and filter, close panels", "esc"),
+		Help:    binding("help", "?"),
+		Edit:    binding("edit in $EDITOR", "e"),
+		Command: binding("open another root, like :xr.example.io/name -n ns", ":"),
+
+		Inspect:          binding("inspect", "y", "enter"),
+		Toggle:           binding("expand or collapse", "x", "space"),
+		Expand:           binding("expand subtree", "+"),
+		Collapse:         binding("collapse subtree", "-"),
+		ExpandAll:        binding("expand all", ">"),
+		CollapseAll:      binding("collapse all", "<"),
+		Search:           binding("search", "/"),
+		NextMatch:        binding("next match", "n"),
+		PrevMatch:        binding("previous match", "N"),
+		Filter:           binding("filter by health", "f"),
+		Usage:            binding("toggle usage view", "u"),
+		Reconcile:        binding("reconcile", "r"),
+		ReconcileSubtree: binding("reconcile subtree", "R"),
+		Suspend:          binding("suspend/resume (Flux)", "s"),
+		Pause:            binding("pause/unpause (Crossplane)", "p"),
+		PauseSubtree:     binding("pause/unpause subtree (Crossplane)", "P"),
+		Delete:           binding("delete", "D"),
+		Stuck:            binding("stuck deletions", "F"),
+		Why:              binding("why not ready", "w"),
+		Focus:            binding("focus on the node as the root, back returns", "o"),
+		Split:            binding("toggle details next to the tree", "v"),
+		Timeline:         binding("condition changes of the node", "t"),
+		DetailsDown:      binding("scroll details down", "ctrl+d"),
+		DetailsUp:        binding("scroll details up", "ctrl+u"),
+
+		SortWorst: binding("sort by worst status", "S"),
+
+		NextTab: binding("next tab, 1-9 go to a tab", "tab"),
+		PrevTab: binding("previous tab", "shift+tab"),
+
+		Top:       binding("top", "g"),
+		Bottom:    binding("bottom", "G"),
+		Copy:      binding("copy YAML", "c"),
+		Diff:      binding("diff revisions", "d"),
+		FromOlder: binding("older from revision", "["),
+		FromNewer: binding("newer from revision", "]"),
+		ToOlder:   binding("older to revision", "{"),
+		ToNewer:   binding("newer to revision", "}"),
+	}
+}
+
+// keyAction names a binding for the config file and the help overlay.
+type keyAction struct {
+	name    string
+	section string
+	binding *key.Binding
+}
+
+// actions is the table of all bindings, in the order they are listed in
+// the help overlay.
+func (k *keyMap) actions() []keyAction {
+	return []keyAction{
+		{"quit", "General", &k.Quit},
+		{"back", "General", &k.Back},
+		{"clear", "General", &k.Clear},
+		{"help", "General", &k.Help},
+		{"edit", "General", &k.Edit},
+		{"command", "General", &k.Command},
+
+		{"inspect", "Tree", &k.Inspect},
+		{"toggle", "Tree", &k.Toggle},
+		{"expand", "Tree", &k.Expand},
+		{"collapse", "Tree", &k.Collapse},
+		{"expand-all", "Tree", &k.ExpandAll},
+		{"collapse-all", "Tree", &k.CollapseAll},
+		{"search", "Tree", &k.Search},
+		{"next-match", "Tree", &k.NextMatch},
+		{"previous-match", "Tree", &k.PrevMatch},
+		{"filter", "Tree", &k.Filter},
+		{"usage", "Tree", &k.Usage},
+		{"reconcile", "Tree", &k.Reconcile},
+		{"reconcile-subtree", "Tree", &k.ReconcileSubtree},
+		{"suspend", "Tree", &k.Suspend},
+		{"pause", "Tree", &k.Pause},
+		{"pause-subtree", "Tree", &k.PauseSubtree},
+		{"delete", "Tree", &k.Delete},
+		{"stuck", "Tree", &k.Stuck},
+		{"why", "Tree", &k.Why},
+		{"focus", "Tree", &k.Focus},
+		{"split", "Tree", &k.Split},
+		{"timeline", "Tree", &k.Timeline},
+		{"details-down", "Tree", &k.DetailsDown},
+		{"details-up", "Tree", &k.DetailsUp},
+
+		{"sort-worst", "Overview", &k.SortWorst},
+
+		{"next-tab", "Tabs", &k.NextTab},
+		{"previous-tab", "Tabs", &k.PrevTab},
+
+		{"top", "Viewer", &k.Top},
+		{"bottom", "Viewer", &k.Bottom},
+		{"copy", "Viewer", &k.Copy},
+		{"diff", "Viewer", &k.Diff},
+		{"from-older", "Viewer", &k.FromOlder},
+		{"from-newer", "Viewer", &k.FromNewer},
+		{"to-older", "Viewer", &k.ToOlder},
+		{"to-newer", "Viewer", &k.ToNewer},
+	}
+}
+
+// apply rebinds the actions named in overrides to the given keys.
+func (k *keyMap) apply(overrides map[string][]string) error {
+	actions := map[string]keyAction{}
+	for _, a := range k.actions() {
+		actions[a.name] = a
+	}
+
+	names := make([]string, 0, len(overrides))
+	for name := range overrides {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	for _, name := range names {
+		a, ok := actions[name]
+		if !ok {
+			return fmt.Errorf("unknown key action %q", name)
+		}
+
+		keys := overrides[name]
+		if len(keys) == 0 {
+			return fmt.Errorf("key action %q: no keys given", name)
+		}
+
+		desc := a.binding.Help().Desc
+		*a.binding = binding(desc, keys...)
+	}
+
+	return k.conflicts()
+}
+
+// views are the sections of the bindings active together in a view. A key
+// bound twice in a view only triggers the action handled first. The keys of
+// the tree are ignored while the viewer is open, those of the tabs are not.
+var views = [][]string{
+	{"General", "Tree", "Tabs"},
+	{"General", "Overview"},
+	{"General", "Viewer", "Tabs"},
+}
+
+// conflicts returns an error for the first key bound to two actions of a view.
+func (k *keyMap) conflicts() error {
+	actions := k.actions()
+
+	for _, sections := range views {
+		bound := map[string]string{}
+		for _, a := range actions {
+			if !slices.Contains(sections, a.section) {
+				continue
+			}
+
+			for _, pressed := range a.binding.Keys() {
+				if other, ok := bound[pressed]; ok && other != a.name {
+					return fmt.Errorf("key %q is bound to both %q and %q", pressed, other, a.name)
+				}
+				bound[pressed] = a.name
+			}
+		}
+	}
+
+	return nil
+}
+
+// ValidateKeys checks the key bindings of a config file.
+func ValidateKeys(overrides map[string][]string) error {
+	k := defaultKeyMap()
+	return k.apply(overrides)
+}
+
+// inSection reports whether msg is bound to an action of section.
+func (k *keyMap) inSection(msg tea.KeyPressMsg, section string) bool {
+	for _, a := range k.actions() {
+		if a.section == section && key.Matches(msg, *a.binding) {
+			return true
+		}
+	}
+	return false
+}
+
+// help renders every binding by section for the help overlay.
+func (k *keyMap) help() string {
+	var b strings.Builder
+
+	section := ""
+	for _, a := range k.actions() {
+		if a.section != section {
+			if section != "" {
+				b.WriteString("\n")
+			}
+			section = a.section
+			fmt.Fprintf(&b, "%s\n", section)
+		}
+		h := a.binding.Help()
+		fmt.Fprintf(&b, "  %-16s %s\n", h.Key, h.Desc)
+	}
+
+	return b.String()
+}
+
+// shortHelp renders bindings for a footer, like `y/enter inspect • / search`.
+func shortHelp(bindings ...key.Binding) string {
+	parts := make([]string, 0, len(bindings))
+	for _, b := range bindings {
+		if !b.Enabled() {
+			continue
+		}
+		parts = append(parts, b.Help().Key+" "+shortDesc(b.Help().Desc))
+	}
+	return strings.Join(parts, " • ")
+}
+
+// shortDesc cuts a description at the first comma or parenthesis.
+func shortDesc(desc string) string {
+	if i := strings.IndexAny(desc, ",("); i > 0 {
+		return strings.TrimSpace(desc[:i])
+	}
+	return desc
+}
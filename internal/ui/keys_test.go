@@ -0,0 +1,80 @@
+package ui
+
+import (
+	"testing"
+
+	tea "charm.land/bubbletea/v2"
+	"github.com/nkzk/xrefs/internal/config"
+	"github.com/nkzk/xrefs/internal/models"
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+)
+
+func TestApplyKeys(t *testing.T) {
+	tests := []struct {
+		name      string
+		overrides map[string][]string
+		valid     bool
+	}{
+		{name: "defaults", valid: true},
+		{name: "rebind", overrides: map[string][]string{"reconcile": {"ctrl+r"}}, valid: true},
+		{name: "swap", overrides: map[string][]string{"reconcile": {"q"}, "back": {"r"}}, valid: true},
+		{name: "same key in other views", overrides: map[string][]string{"copy": {"r"}, "sort-worst": {"r"}}, valid: true},
+		{name: "unknown action", overrides: map[string][]string{"bogus": {"x"}}},
+		{name: "no keys", overrides: map[string][]string{"reconcile": {}}},
+		{name: "general and tree", overrides: map[string][]string{"reconcile": {"q"}}},
+		{name: "general and viewer", overrides: map[string][]string{"copy": {"e"}}},
+		{name: "tabs and viewer", overrides: map[string][]string{"copy": {"tab"}}},
+		{name: "within the tree", overrides: map[string][]string{"pause": {"p", "r"}}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			k := defaultKeyMap()
+			err := k.apply(tt.overrides)
+			if tt.valid && err != nil {
+				t.Errorf("unexpected error: %v", err)
+			}
+			if !tt.valid && err == nil {
+				t.Error("expected an error")
+			}
+		})
+	}
+
+	k := defaultKeyMap()
+	if err := k.apply(map[string][]string{"reconcile": {"ctrl+r"}}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if got := k.Reconcile.Help(); got.Key != "ctrl+r" || got.Desc != "reconcile" {
+		t.Errorf("expected reconcile on ctrl+r, got %s %s", got.Key, got.Desc)
+	}
+}
+
+func TestViewerOwnsTreeKeys(t *testing.T) {
+	u := &unstructured.Unstructured{}
+	u.SetAPIVersion("example.io/v1")
+	u.SetKind("XR")
+	u.SetName("example")
+	root := models.NewResource(nil, u, &v1.ObjectReference{APIVersion: "example.io/v1", Kind: "XR", Name: "example"})
+
+	// copy shares its key with reconcile, which is valid as the viewer ignores
+	// the keys of the tree
+	overrides := map[string][]string{"copy": {"r"}}
+	if err := ValidateKeys(overrides); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	m := NewModel(root, Config{Settings: config.Config{Keys: overrides}})
+	m.resourceViewModel.SetResource(root)
+	m.showViewport = true
+
+	updated, _ := m.Update(tea.KeyPressMsg{Code: 'r', Text: "r"})
+	got := updated.(Model)
+
+	if got.confirm != nil {
+		t.Errorf("expected r not to ask to reconcile while the viewer is open, got %q", got.confirm.prompt)
+	}
+	if got.resourceViewModel.status != "copied to clipboard" {
+		t.Errorf("expected r to copy in the viewer, got status %q", got.resourceViewModel.status)
+	}
+}
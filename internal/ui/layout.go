@@ -0,0 +1,177 @@
+package ui
+
+import (
+	"strings"
+
+	"charm.land/bubbles/v2/list"
+	lipgloss "charm.land/lipgloss/v2"
+	"github.com/charmbracelet/x/ansi"
+	"github.com/nkzk/xrefs/internal/config"
+	"github.com/nkzk/xrefs/internal/models"
+)
+
+const (
+	// minTreeWidth is the width below which columns are dropped rather than
+	// truncating the tree further.
+	minTreeWidth = 30
+
+	// minLastWidth is the least room left for the last column, which
+	// otherwise takes the rest of the line.
+	minLastWidth = 12
+)
+
+// layout is the widths of the tree and the columns that fit the terminal.
+type layout struct {
+	tree    int
+	columns []config.Column
+	widths  []int // the last column takes the rest of the line
+}
+
+// newLayout sizes the columns to their content in items, and drops columns
+// by priority until the tree and the remaining columns fit in width.
+func newLayout(columns []config.Column, items []list.Item, width int) layout {
+	tree := lipgloss.Width("RESOURCE")
+	natural := make([]int, len(columns))
+	for i, c := range columns {
+		natural[i] = lipgloss.Width(c.Header)
+	}
+
+	for _, item := range items {
+		r, ok := item.(models.Resource)
+		if !ok {
+			continue
+		}
+		tree = max(tree, lipgloss.Width(treeName(r)))
+		for i, c := range columns {
+			natural[i] = max(natural[i], min(lipgloss.Width(cell(c, r)), maxColumnWidth(c)))
+		}
+	}
+
+	visible := make([]int, len(columns))
+	for i := range columns {
+		visible[i] = i
+	}
+
+	// room taken by the columns, counting the last one at its minimum
+	used := func() int {
+		n := 0
+		for j, i := range visible {
+			w := natural[i]
+			if j == len(visible)-1 {
+				w = min(w, minLastWidth)
+			}
+			n += w + 1
+		}
+		if len(visible) > 0 {
+			n++ // two spaces after the tree
+		}
+		return n
+	}
+
+	for len(visible) > 0 && width-used() < min(tree, minTreeWidth) {
+		visible = dropColumn(columns, visible)
+	}
+
+	l := layout{
+		tree: max(min(tree, width-used()), 1),
+	}
+
+	rest := width - l.tree
+	for j, i := range visible {
+		w := natural[i]
+		if j == len(visible)-1 {
+			w = rest - 1
+			if j == 0 {
+				w--
+			}
+		}
+		l.columns = append(l.columns, columns[i])
+		l.widths = append(l.widths, w)
+		rest -= w + 1
+		if j == 0 {
+			rest--
+		}
+	}
+
+	return l
+}
+
+// dropColumn removes the column with the lowest priority from visible.
+func dropColumn(columns []config.Column, visible []int) []int {
+	lowest := 0
+	for j, i := range visible {
+		if columnPriority(columns[i]) >= columnPriority(columns[visible[lowest]]) {
+			lowest = j
+		}
+	}
+	return append(visible[:lowest:lowest], visible[lowest+1:]...)
+}
+
+// columnPriority orders the columns by how useful they are, lower is kept
+// longer on narrow terminals.
+func columnPriority(c config.Column) int {
+	priorities := []config.ColumnType{
+		config.ColumnReady,
+		config.ColumnReason,
+		config.ColumnHealth,
+		config.ColumnSynced,
+		config.ColumnCondition,
+		config.ColumnJSONPath,
+		config.ColumnNamespace,
+		config.ColumnAge,
+	}
+	for i, t := range priorities {
+		if c.Type == t {
+			return i
+		}
+	}
+	return len(priorities)
+}
+
+// maxColumnWidth caps the width of a column, so a single long value does
+// not push the other columns off screen.
+func maxColumnWidth(c config.Column) int {
+	switch c.Type {
+	case config.ColumnReason:
+		return 80
+	case config.ColumnJSONPath:
+		return 40
+	}
+	return 24
+}
+
+// row lays out the tree and the cells of the visible columns.
+func (l layout) row(tree string, cells []string) string {
+	var b strings.Builder
+	b.WriteString(fit(tree, l.tree))
+
+	for i, w := range l.widths {
+		b.WriteString(" ")
+		if i == 0 {
+			b.WriteString(" ")
+		}
+
+		if i == len(l.widths)-1 {
+			b.WriteString(ansi.Truncate(cells[i], max(w, 1), "…"))
+			break
+		}
+		b.WriteString(fit(cells[i], w))
+	}
+
+	return b.String()
+}
+
+// headers returns the headers of the visible columns.
+func (l layout) headers() []string {
+	headers := make([]string, 0, len(l.columns))
+	for _, c := range l.columns {
+		headers = append(headers, c.Header)
+	}
+	return headers
+}
+
+// fit truncates or pads s to exactly width cells on screen.
+func fit(s string, width int) string {
+	s = ansi.Truncate(s, width, "…")
+	return s + strings.Repeat(" ", max(width-lipgloss.Width(s), 0))
+}
@@ -0,0 +1,253 @@
+package ui
+
+import (
+	"fmt"
+	"sort"
+
+	"charm.land/bubbles/v2/key"
+	"charm.land/bubbles/v2/list"
+	tea "charm.land/bubbletea/v2"
+	"github.com/nkzk/xrefs/internal/models"
+)
+
+// OverviewConfig configures the overview of the roots of a cluster.
+type OverviewConfig struct {
+	Config
+
+	// List lists the roots shown in the overview.
+	List func() ([]*models.Resource, error)
+
+	// Load loads the whole tree of a root, for the health of its subtree.
+	// The returned tree keeps the ID of the root.
+	Load func(root *models.Resource) (*models.Resource, error)
+}
+
+// Overview lists the roots of a cluster, like every composite resource and
+// Kustomization, with the health of their whole trees.
+type Overview struct {
+	list     list.Model
+	delegate resourceDelegate
+	keys     *keyMap
+
+	listRoots func() ([]*models.Resource, error)
+	load      func(root *models.Resource) (*models.Resource, error)
+
+	roots      []*models.Resource
+	loaded     int
+	worstFirst bool
+
+	status    string
+	statusErr bool
+
+	selected *models.Resource
+}
+
+type (
+	overviewRootsMsg struct {
+		roots []*models.Resource
+		err   error
+	}
+
+	overviewTreeMsg struct {
+		tree *models.Resource
+		err  error
+	}
+)
+
+// NewOverview creates the overview, using the theme, columns and keys of cfg.
+func NewOverview(cfg OverviewConfig) Overview {
+	if cfg.Theme != nil {
+		setTheme(*cfg.Theme)
+	}
+
+	delegate := NewResourceDelegate()
+	delegate.columns = cfg.Settings.ColumnsFor("", "")
+
+	l := list.New(nil, delegate, 120, 24)
+	l.SetShowTitle(false)
+	l.SetShowStatusBar(false)
+	l.SetShowHelp(false)
+	l.KeyMap.Quit.SetEnabled(false)
+	l.KeyMap.ForceQuit.SetEnabled(false)
+	l.KeyMap.ShowFullHelp.SetEnabled(false)
+	l.KeyMap.CloseFullHelp.SetEnabled(false)
+
+	keys := defaultKeyMap()
+	if err := keys.apply(cfg.Settings.Keys); err != nil {
+		keys = defaultKeyMap() // validated by the caller, see ValidateKeys
+	}
+
+	return Overview{
+		list:      l,
+		delegate:  delegate,
+		keys:      &keys,
+		listRoots: cfg.List,
+		load:      cfg.Load,
+		status:    "listing roots…",
+	}
+}
+
+// Selected returns the root chosen to open in the tree view, nil when the
+// overview was quit.
+func (o Overview) Selected() *models.Resource {
+	return o.selected
+}
+
+// Init lists the roots, again each time the overview is shown.
+func (o Overview) Init() tea.Cmd {
+	listRoots := o.listRoots
+	return func() tea.Msg {
+		roots, err := listRoots()
+		return overviewRootsMsg{roots: roots, err: err}
+	}
+}
+
+func (o Overview) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
+	switch msg := msg.(type) {
+	case overviewRootsMsg:
+		if msg.err != nil {
+			o.status, o.statusErr = fmt.Sprintf("cannot list roots: %v", msg.err), true
+			return o, nil
+		}
+
+		o.roots, o.loaded = msg.roots, 0
+		o.status, o.statusErr = "", false
+
+		cmds := []tea.Cmd{o.setItems()}
+		for _, r := range o.roots {
+			cmds = append(cmds, o.loadTree(r))
+		}
+		return o, tea.Batch(cmds...)
+
+	case overviewTreeMsg:
+		o.loaded++
+		if msg.err != nil {
+			o.status, o.statusErr = msg.err.Error(), true
+			return o, nil
+		}
+
+		for i, r := range o.roots {
+			if r.ID == msg.tree.ID {
+				o.roots[i] = msg.tree
+			}
+		}
+		return o, o.setItems()
+
+	case tea.WindowSizeMsg:
+		h, v := docStyle.GetFrameSize()
+		// leave room for the title, the columns and the footer
+		o.list.SetSize(msg.Width-h, msg.Height-v-3)
+		o.relayout()
+		return o, nil
+
+	case tea.KeyPressMsg:
+		if o.list.FilterState() == list.Filtering {
+			break
+		}
+
+		switch {
+		case key.Matches(msg, o.keys.Quit, o.keys.Back):
+			o.selected = nil
+			return o, tea.Quit
+
+		case key.Matches(msg, o.keys.SortWorst):
+			o.worstFirst = !o.worstFirst
+			return o, o.setItems()
+
+		case msg.String() == "enter":
+			r, ok := o.list.SelectedItem().(models.Resource)
+			if !ok {
+				return o, nil
+			}
+			for _, root := range o.roots {
+				if root.ID == r.ID {
+					o.selected = root
+				}
+			}
+			return o, tea.Quit
+		}
+	}
+
+	var cmd tea.Cmd
+	o.list, cmd = o.list.Update(msg)
+	return o, cmd
+}
+
+// loadTree loads the subtree of r in the background.
+func (o Overview) loadTree(r *models.Resource) tea.Cmd {
+	load := o.load
+	return func() tea.Msg {
+		tree, err := load(r)
+		if err != nil {
+			err = fmt.Errorf("cannot load %s: %w", displayName(r), err)
+		}
+		return overviewTreeMsg{tree: tree, err: err}
+	}
+}
+
+// setItems lists the roots, the worst first when sorted by status.
+func (o *Overview) setItems() tea.Cmd {
+	roots := append([]*models.Resource(nil), o.roots...)
+	if o.worstFirst {
+		sort.SliceStable(roots, func(i, j int) bool {
+			return worst(roots[i]) > worst(roots[j])
+		})
+	}
+
+	items := make([]list.Item, 0, len(roots))
+	for _, r := range roots {
+		items = append(items, *r)
+	}
+
+	cmd := o.list.SetItems(items)
+	o.relayout()
+	return cmd
+}
+
+func (o *Overview) relayout() {
+	o.delegate.layout = newLayout(o.delegate.columns, o.list.Items(), o.list.Width())
+	o.list.SetDelegate(o.delegate)
+}
+
+// worst returns the worst severity of r and its descendants.
+func worst(r *models.Resource) models.Severity {
+	return max(r.Severity(), r.Rollup().Worst)
+}
+
+func (o Overview) View() tea.View {
+	failing := 0
+	for _, r := range o.roots {
+		if worst(r) > models.SeverityUnknown {
+			failing++
+		}
+	}
+
+	title := panelTitleStyle.Render("Overview") +
+		mutedStyle.Render(fmt.Sprintf(" %d roots, %d failing", len(o.roots), failing))
+	if o.loaded < len(o.roots) {
+		title += mutedStyle.Render(fmt.Sprintf(" • loading trees %d/%d", o.loaded, len(o.roots)))
+	}
+
+	columns := mutedStyle.Render(o.delegate.layout.row("RESOURCE", o.delegate.layout.headers()))
+
+	sortBy := "sort by worst"
+	if o.worstFirst {
+		sortBy = "sort by name"
+	}
+
+	footer := mutedStyle.Render(fmt.Sprintf(
+		"↑/↓ navigate • / filter • enter open • %s %s • %s quit",
+		o.keys.SortWorst.Help().Key, sortBy, o.keys.Back.Help().Key,
+	))
+	if o.status != "" {
+		style := mutedStyle
+		if o.statusErr {
+			style = errorStyle
+		}
+		footer += " • " + style.Render(o.status)
+	}
+
+	v := tea.NewView(docStyle.Render(title + "\n" + columns + "\n" + o.list.View() + "\n" + footer))
+	v.AltScreen = true
+	return v
+}
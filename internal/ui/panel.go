@@ -0,0 +1,52 @@
+package ui
+
+import (
+	"strings"
+
+	"charm.land/bubbles/v2/viewport"
+	tea "charm.land/bubbletea/v2"
+)
+
+// panelModel shows a scrollable text report, like a deletion plan, in place
+// of the tree.
+type panelModel struct {
+	viewport viewport.Model
+	title    string
+}
+
+func newPanelModel() panelModel {
+	return panelModel{
+		viewport: viewport.New(),
+	}
+}
+
+// SetContent replaces the report and scrolls to the top.
+func (m *panelModel) SetContent(title, content string) {
+	m.title = title
+	m.viewport.SetContent(content)
+	m.viewport.GotoTop()
+}
+
+func (m panelModel) Update(msg tea.Msg) (panelModel, tea.Cmd) {
+	if msg, ok := msg.(tea.WindowSizeMsg); ok {
+		h, v := docStyle.GetFrameSize()
+		m.viewport.SetWidth(msg.Width - h)
+		// leave room for the title and a footer of up to two lines
+		m.viewport.SetHeight(msg.Height - v - 4)
+		return m, nil
+	}
+
+	var cmd tea.Cmd
+	m.viewport, cmd = m.viewport.Update(msg)
+	return m, cmd
+}
+
+// View renders the panel with the given footer.
+func (m panelModel) View(footer string) string {
+	return strings.Join([]string{
+		panelTitleStyle.Render(m.title),
+		"",
+		m.viewport.View(),
+		footer,
+	}, "\n")
+}
@@ -0,0 +1,107 @@
+package ui
+
+import (
+	"fmt"
+
+	"charm.land/bubbles/v2/key"
+	"charm.land/bubbles/v2/list"
+	tea "charm.land/bubbletea/v2"
+	"github.com/nkzk/xrefs/internal/models"
+)
+
+// Picker lists candidate roots with their status, for choosing the root
+// when the target is given by its type only.
+type Picker struct {
+	list     list.Model
+	delegate resourceDelegate
+	keys     *keyMap
+	title    string
+
+	selected *models.Resource
+}
+
+// NewPicker lists the candidates, using the theme, columns and keys of cfg.
+func NewPicker(title string, candidates []*models.Resource, cfg Config) Picker {
+	if cfg.Theme != nil {
+		setTheme(*cfg.Theme)
+	}
+
+	delegate := NewResourceDelegate()
+	delegate.columns = cfg.Settings.ColumnsFor("", "")
+
+	items := make([]list.Item, 0, len(candidates))
+	for _, c := range candidates {
+		items = append(items, *c)
+	}
+
+	l := list.New(items, delegate, 120, 24)
+	l.SetShowTitle(false)
+	l.SetShowStatusBar(false)
+	l.SetShowHelp(false)
+	l.KeyMap.Quit.SetEnabled(false)
+	l.KeyMap.ForceQuit.SetEnabled(false)
+	l.KeyMap.ShowFullHelp.SetEnabled(false)
+	l.KeyMap.CloseFullHelp.SetEnabled(false)
+
+	keys := defaultKeyMap()
+	if err := keys.apply(cfg.Settings.Keys); err != nil {
+		keys = defaultKeyMap() // validated by the caller, see ValidateKeys
+	}
+
+	return Picker{
+		list:     l,
+		delegate: delegate,
+		keys:     &keys,
+		title:    title,
+	}
+}
+
+// Selected returns the chosen root, nil when the picker was quit.
+func (p Picker) Selected() *models.Resource {
+	return p.selected
+}
+
+func (p Picker) Init() tea.Cmd { return nil }
+
+func (p Picker) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
+	switch msg := msg.(type) {
+	case tea.WindowSizeMsg:
+		h, v := docStyle.GetFrameSize()
+		// leave room for the title, the columns and the footer
+		p.list.SetSize(msg.Width-h, msg.Height-v-3)
+		p.delegate.layout = newLayout(p.delegate.columns, p.list.Items(), p.list.Width())
+		p.list.SetDelegate(p.delegate)
+		return p, nil
+
+	case tea.KeyPressMsg:
+		if p.list.FilterState() == list.Filtering {
+			break
+		}
+
+		switch {
+		case key.Matches(msg, p.keys.Quit, p.keys.Back):
+			return p, tea.Quit
+
+		case msg.String() == "enter":
+			if r, ok := p.list.SelectedItem().(models.Resource); ok {
+				p.selected = &r
+			}
+			return p, tea.Quit
+		}
+	}
+
+	var cmd tea.Cmd
+	p.list, cmd = p.list.Update(msg)
+	return p, cmd
+}
+
+func (p Picker) View() tea.View {
+	title := panelTitleStyle.Render(fmt.Sprintf("%s (%d)", p.title, len(p.list.Items())))
+	columns := mutedStyle.Render(p.delegate.layout.row("RESOURCE", p.delegate.layout.headers()))
+
+	footer := mutedStyle.Render("↑/↓ navigate • / filter • enter open • " + p.keys.Back.Help().Key + " quit")
+
+	v := tea.NewView(docStyle.Render(title + "\n" + columns + "\n" + p.list.View() + "\n" + footer))
+	v.AltScreen = true
+	return v
+}
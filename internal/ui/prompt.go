@@ -0,0 +1,135 @@
+package ui
+
+import (
+	"strings"
+	"time"
+
+	"charm.land/bubbles/v2/key"
+	"charm.land/bubbles/v2/textinput"
+	tea "charm.land/bubbletea/v2"
+	"github.com/nkzk/xrefs/internal/models"
+)
+
+// prompt is the `:` command prompt opening another root in place of the
+// current one.
+type prompt struct {
+	input textinput.Model
+
+	// seq identifies the latest input, so completions of an input that was
+	// already changed are dropped.
+	seq int
+}
+
+type (
+	// completeMsg completes the input seq once typing has paused.
+	completeMsg struct {
+		seq   int
+		input string
+	}
+
+	completionsMsg struct {
+		seq         int
+		completions []string
+	}
+
+	// openedMsg is the result of opening the root typed in the prompt.
+	openedMsg struct {
+		root  *models.Resource
+		query string
+		err   error
+	}
+)
+
+func newPrompt() *prompt {
+	p := &prompt{input: textinput.New()}
+	p.input.Prompt = ":"
+	p.input.Placeholder = "TYPE[.VERSION][.GROUP]/NAME [-n NAMESPACE]"
+	p.input.ShowSuggestions = true
+	return p
+}
+
+func (m Model) updatePrompt(msg tea.KeyPressMsg) (Model, tea.Cmd) {
+	p := m.prompt
+
+	if key.Matches(msg, m.keys.Quit) {
+		return m, tea.Quit
+	}
+
+	switch msg.String() {
+	case "esc":
+		m.prompt = nil
+		return m, nil
+
+	case "enter":
+		m.prompt = nil
+		query := strings.TrimSpace(p.input.Value())
+		if query == "" {
+			return m, nil
+		}
+
+		m.status, m.statusErr = "opening "+query+"…", false
+		open := m.open
+		return m, func() tea.Msg {
+			root, err := open(query)
+			return openedMsg{root: root, query: query, err: err}
+		}
+	}
+
+	value := p.input.Value()
+
+	var cmd tea.Cmd
+	p.input, cmd = p.input.Update(msg)
+	if p.input.Value() == value || m.complete == nil {
+		return m, cmd
+	}
+
+	p.seq++
+	seq, input := p.seq, p.input.Value()
+	return m, tea.Batch(cmd, tea.Tick(150*time.Millisecond, func(time.Time) tea.Msg {
+		return completeMsg{seq: seq, input: input}
+	}))
+}
+
+// loadCompletions completes the input of msg if it is still the latest.
+func (m Model) loadCompletions(msg completeMsg) tea.Cmd {
+	if m.prompt == nil || msg.seq != m.prompt.seq {
+		return nil
+	}
+
+	complete := m.complete
+	return func() tea.Msg {
+		return completionsMsg{seq: msg.seq, completions: complete(msg.input)}
+	}
+}
+
+// setCompletions offers the completions as suggestions of the prompt.
+func (m *Model) setCompletions(msg completionsMsg) {
+	if m.prompt == nil || msg.seq != m.prompt.seq {
+		return
+	}
+	m.prompt.input.SetSuggestions(msg.completions)
+}
+
+// openRoot replaces the tree with the root opened from the prompt.
+func (m *Model) openRoot(msg openedMsg) tea.Cmd {
+	if msg.err != nil {
+		m.status, m.statusErr = msg.err.Error(), true
+		return nil
+	}
+
+	m.status, m.statusErr = "opened "+displayName(msg.root), false
+	m.crumbs = nil
+	m.list.Select(0)
+
+	return tea.Batch(m.setRoot(msg.root), m.watchRoot(msg.root))
+}
+
+// view renders the prompt with the suggestions matching the input.
+func (p *prompt) view(width int) string {
+	suggestions := p.input.MatchedSuggestions()
+	if len(suggestions) == 0 {
+		return p.input.View()
+	}
+
+	return p.input.View() + "\n" + fit(mutedStyle.Render(strings.Join(suggestions, "  ")), width)
+}
@@ -6,10 +6,15 @@ import (
 	"strings"
 	"time"
 
+	"charm.land/bubbles/v2/key"
 	"charm.land/bubbles/v2/list"
 	tea "charm.land/bubbletea/v2"
 	lipgloss "charm.land/lipgloss/v2"
+	"github.com/nkzk/xrefs/internal/config"
+	"github.com/nkzk/xrefs/internal/diagnose"
+	"github.com/nkzk/xrefs/internal/k8s"
 	"github.com/nkzk/xrefs/internal/models"
+	"k8s.io/apimachinery/pkg/util/duration"
 )
 
 type Sort string
@@ -19,36 +24,147 @@ const (
 	UsageSort   Sort = "usage"
 )
 
+// Config holds the dependencies of the TUI.
+type Config struct {
+	// Client is used for actions against the cluster, like applying edits.
+	Client k8s.Client
+
+	// StuckThreshold is how long a deletion may be pending before it is
+	// reported as stuck. Defaults to diagnose.DefaultStuckThreshold.
+	StuckThreshold time.Duration
+
+	// Expand asks for the subtree of the resource with the given ID to be
+	// loaded depth levels down, or entirely when depth is negative, instead
+	// of waiting for the next refresh. It must not block.
+	Expand func(id string, depth int)
+
+	// Settings is the user configuration, like the columns to show.
+	Settings config.Config
+
+	// Theme is the colour palette, the dark theme when nil.
+	Theme *config.Theme
+
+	// Watch switches the producer of UpdateResourceMsg to another root,
+	// used to focus on a node of the tree. Focusing is disabled when nil.
+	Watch func(root *models.Resource) error
+
+	// Open resolves a root typed in the command prompt, replacing the tree
+	// with it. The prompt is disabled when nil.
+	Open func(query string) (*models.Resource, error)
+
+	// Complete returns the completions of the input of the command prompt.
+	Complete func(input string) []string
+}
+
 type Model struct {
-	list list.Model
+	list     list.Model
+	delegate resourceDelegate
+	keys     *keyMap
+	client   k8s.Client
+	expand   func(id string, depth int)
+	watch    func(root *models.Resource) error
+	open     func(query string) (*models.Resource, error)
+	complete func(input string) []string
+	settings config.Config
+
+	stuckThreshold time.Duration
 
 	sort              Sort
+	healthFilter      HealthFilter
 	resourceViewModel resourceViewModel
 	showViewport      bool
+	panel             panelModel
+	showPanel         bool
+	details           detailsModel
+	split             bool
+
+	width  int
+	height int
+
+	root           *models.Resource
+	usageRoot      *models.Resource // pre-built usage-sorted tree
+	crumbs         []crumb          // roots focused away from, the last is the parent view
+	rootUpdatedAt  time.Time
+	rootDeletedAt  time.Time
+	followDeletion bool // the tree is refreshed until the deletion completes
+	deletionDone   bool
+
+	status    string
+	statusErr bool
+	confirm   *confirmation
+	deletion  *pendingDeletion
+	search    *search
+	prompt    *prompt
+
+	back bool // left with the back key from the root, rather than quit
+}
+
+// Back reports whether the tui was left with the back key from the root,
+// rather than quit, so the caller can return to where the root was chosen.
+func (m Model) Back() bool {
+	return m.back
+}
+
+// columnsFor returns the columns of the tree of root. A selection of a single
+// type takes the columns of that type.
+func columnsFor(settings config.Config, root *models.Resource) []config.Column {
+	if k8s.IsSelection(root) {
+		if _, types := k8s.SelectionQuery(root); len(types) == 1 {
+			return settings.ColumnsFor(types[0].APIVersion, types[0].Kind)
+		}
+		return settings.ColumnsFor("", "")
+	}
 
-	root          *models.Resource
-	usageRoot     *models.Resource // pre-built usage-sorted tree
-	rootUpdatedAt time.Time
+	return settings.ColumnsFor(root.Ref.APIVersion, root.Ref.Kind)
 }
 
-func NewModel(root *models.Resource) *Model {
+func NewModel(root *models.Resource, cfg Config) *Model {
+	if cfg.Theme != nil {
+		setTheme(*cfg.Theme)
+	}
+
 	delegate := NewResourceDelegate()
+	delegate.columns = columnsFor(cfg.Settings, root)
 
 	l := list.New(flatten(*root, 0), delegate, 120, 24)
 	l.SetShowTitle(false)
 	l.SetShowStatusBar(false)
 	l.SetShowHelp(false)
-	l.SetFilteringEnabled(true)
+	// the list filter only sees expanded rows, search is done on the tree instead
+	l.SetFilteringEnabled(false)
+	// quitting and help are handled by the model, so they follow the key config
+	l.KeyMap.Quit.SetEnabled(false)
+	l.KeyMap.ForceQuit.SetEnabled(false)
+	l.KeyMap.ShowFullHelp.SetEnabled(false)
+	l.KeyMap.CloseFullHelp.SetEnabled(false)
+
+	keys := defaultKeyMap()
+	if err := keys.apply(cfg.Settings.Keys); err != nil {
+		keys = defaultKeyMap() // validated by the caller, see ValidateKeys
+	}
+
+	if cfg.StuckThreshold == 0 {
+		cfg.StuckThreshold = diagnose.DefaultStuckThreshold
+	}
 
 	return &Model{
 		list:              l,
+		delegate:          delegate,
+		keys:              &keys,
+		client:            cfg.Client,
+		expand:            cfg.Expand,
+		watch:             cfg.Watch,
+		open:              cfg.Open,
+		complete:          cfg.Complete,
+		settings:          cfg.Settings,
+		stuckThreshold:    cfg.StuckThreshold,
 		root:              root,
-		resourceViewModel: newResourceViewModel(),
+		resourceViewModel: newResourceViewModel(&keys),
+		panel:             newPanelModel(),
+		details:           newDetailsModel(),
 	}
 }
 
-var docStyle = lipgloss.NewStyle().Margin(1, 2)
-
 type (
 	SortMsg struct {
 		Type Sort
@@ -58,8 +174,11 @@ type (
 		Resource *models.Resource
 	}
 
+	// ExpandNodeMsg requests the subtree of an expanded resource to be loaded
+	// Depth levels down, or entirely when Depth is negative.
 	ExpandNodeMsg struct {
 		Resource *models.Resource
+		Depth    int
 	}
 
 	UpdateUsageTreeMsg struct {
@@ -74,22 +193,119 @@ type (
 	RootErrMsg struct {
 		Err error
 	}
-	RootDeletedMsg struct{}
+	RootDeletedMsg struct {
+		// Follow is set when the producer keeps refreshing the tree until all
+		// of its resources are gone.
+		Follow bool
+	}
+
+	// DeletionCompleteMsg is sent when all resources of a deleted root are gone.
+	DeletionCompleteMsg struct{}
 )
 
 func (m Model) Init() tea.Cmd { return nil }
 
 func (m Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
+	next, cmd := m.update(msg)
+
+	// the details of the split view follow the cursor
+	n, ok := next.(Model)
+	if !ok || !n.split {
+		return next, cmd
+	}
+
+	var sync tea.Cmd
+	if msg, ok := msg.(UpdateResourceMsg); ok && msg.Resource == n.root {
+		sync = n.details.Refresh(n.selectedResource())
+	} else {
+		sync = n.details.SetResource(n.selectedResource())
+	}
+
+	return n, tea.Batch(cmd, sync)
+}
+
+func (m Model) update(msg tea.Msg) (tea.Model, tea.Cmd) {
 	switch msg := msg.(type) {
 
+	case loadEventsMsg:
+		return m, m.details.loadEvents(m.client, msg.seq)
+
+	case eventsMsg:
+		m.details.setEvents(msg)
+		return m, nil
+
+	case clearHighlightMsg:
+		return m, nil
+
+	case completeMsg:
+		return m, m.loadCompletions(msg)
+
+	case completionsMsg:
+		m.setCompletions(msg)
+		return m, nil
+
+	case openedMsg:
+		return m, m.openRoot(msg)
+
+	case clearStatusMsg:
+		if !m.statusErr {
+			m.status = ""
+		}
+		m.resourceViewModel.status = ""
+		return m, nil
+
+	case editFinishedMsg:
+		return m, m.applyEdit(msg)
+
+	case actionResultMsg:
+		if msg.err != nil {
+			m.status = msg.err.Error()
+			m.statusErr = true
+			m.resourceViewModel.status = "error, see list view"
+			return m, nil
+		}
+
+		m.status = msg.message
+		m.statusErr = false
+		m.resourceViewModel.status = msg.message
+		return m, clearStatusAfter(3 * time.Second)
+
+	case subtreeLoadedMsg:
+		if msg.err != nil {
+			m.status, m.statusErr = msg.err.Error(), true
+			return m, nil
+		}
+
+		m.status, m.statusErr = "", false
+		cmd := msg.then(&m, msg.tree)
+		return m, cmd
+
+	case RootDeletedMsg:
+		m.rootDeletedAt = time.Now()
+		m.followDeletion = msg.Follow
+		return m, nil
+
+	case DeletionCompleteMsg:
+		m.deletionDone = true
+		return m, nil
+
+	case ExpandNodeMsg:
+		if m.expand != nil && m.sort != UsageSort {
+			m.expand(msg.Resource.ID, msg.Depth)
+		}
+		return m, nil
+
 	case restoreCursorMsg:
 		m.list.Select(msg.index)
 		return m, nil
 
 	case UpdateUsageTreeMsg:
+		if msg.Resource.ID != m.root.ID {
+			return m, nil // built for a root that is no longer shown
+		}
 		m.usageRoot = msg.Resource
 		if m.sort == UsageSort {
-			return m, m.list.SetItems(flatten(*m.usageRoot, 0))
+			return m, m.setItems(m.items(m.usageRoot))
 		}
 		return m, nil
 
@@ -99,66 +315,294 @@ func (m Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 		}
 		m.sort = msg.Type
 		if msg.Type == UsageSort {
-			return m, m.list.SetItems(flatten(*m.usageRoot, 0))
+			return m, m.setItems(m.items(m.usageRoot))
 		}
-		return m, m.list.SetItems(flatten(*m.root, 0))
+		return m, m.setItems(m.items(m.root))
 
 	case UpdateResourceMsg:
-		m.root = msg.Resource
+		if msg.Resource != m.root {
+			return m, nil // sent by the producer of a previous root
+		}
 		m.rootUpdatedAt = time.Now()
+		m.resourceViewModel.Refresh(m.root.Find(m.resourceViewModel.resourceID))
 		if m.sort == UsageSort && m.usageRoot != nil {
 			return m, nil // don't refresh list, we're showing usage tree
 		}
-		return m, m.list.SetItems(flatten(*msg.Resource, 0))
+		return m, tea.Batch(m.setItems(m.items(msg.Resource)), clearHighlightAfter(m.root))
 	case tea.KeyPressMsg:
-		if m.list.FilterState() == list.Filtering {
-			break // let list handle it
+		if m.deletion != nil {
+			return m.updateDeletion(msg)
+		}
+
+		if m.search != nil && m.search.typing {
+			return m.updateSearch(msg)
+		}
+
+		if m.prompt != nil {
+			return m.updatePrompt(msg)
 		}
 
-		switch msg.String() {
-		case "ctrl+c":
+		if m.showPanel {
+			switch {
+			case key.Matches(msg, m.keys.Quit):
+				return m, tea.Quit
+			case key.Matches(msg, m.keys.Back, m.keys.Clear):
+				m.showPanel = false
+				return m, nil
+			}
+
+			var cmd tea.Cmd
+			m.panel, cmd = m.panel.Update(msg)
+			return m, cmd
+		}
+
+		if m.confirm != nil {
+			c := m.confirm
+			m.confirm = nil
+			if msg.String() == "y" {
+				return m, c.action
+			}
+			return m, actionResult("cancelled", nil)
+		}
+
+		switch {
+		case key.Matches(msg, m.keys.Quit):
 			return m, tea.Quit
 
-		case "q":
+		case m.showViewport && m.keys.inSection(msg, "Tree"):
+			// the viewer owns its keys, the tree is acted on once it is closed
+
+		case key.Matches(msg, m.keys.Focus):
+			m.status, m.statusErr = "", false
+			return m, m.focus(m.selectedResource())
+
+		case key.Matches(msg, m.keys.Split):
+			m.split = !m.split
+			m.details.resource = nil // reloaded for the selection
+			m.resize()
+			return m, nil
+
+		case key.Matches(msg, m.keys.DetailsDown, m.keys.DetailsUp):
+			if !m.split {
+				break
+			}
+			if key.Matches(msg, m.keys.DetailsDown) {
+				m.details.viewport.HalfPageDown()
+			} else {
+				m.details.viewport.HalfPageUp()
+			}
+			return m, nil
+
+		case key.Matches(msg, m.keys.Help):
+			m.panel.SetContent("Key bindings", m.keys.help())
+			m.showPanel = true
+			return m, nil
+
+		case key.Matches(msg, m.keys.Back):
 			if m.showViewport {
 				m.showViewport = false
 				return m, nil
 			}
 
+			if len(m.crumbs) > 0 {
+				return m, m.unfocus()
+			}
+
+			m.back = true
 			return m, tea.Quit
 
-		case "y", "enter":
-			if !m.showViewport {
-				selected, ok := m.list.SelectedItem().(models.Resource)
-				if ok {
-					m.resourceViewModel.SetResource(&selected)
-					m.showViewport = true
-					return m, nil
+		case key.Matches(msg, m.keys.Edit):
+			m.status, m.statusErr = "", false
+			return m, editResource(m.selectedResource())
+
+		case key.Matches(msg, m.keys.Reconcile):
+			m.status, m.statusErr = "", false
+			c, cmd := m.reconcile(m.selectedResource(), false)
+			m.confirm = c
+			return m, cmd
+
+		case key.Matches(msg, m.keys.ReconcileSubtree):
+			m.status, m.statusErr = "loading the subtree…", false
+			return m, m.loadSubtree(m.selectedResource(), func(m *Model, tree *models.Resource) tea.Cmd {
+				c, cmd := m.reconcile(tree, true)
+				m.confirm = c
+				return cmd
+			})
+
+		case key.Matches(msg, m.keys.Pause):
+			m.status, m.statusErr = "", false
+			c, cmd := m.crossplaneTogglePause(m.selectedResource(), false)
+			m.confirm = c
+			return m, cmd
+
+		case key.Matches(msg, m.keys.PauseSubtree):
+			m.status, m.statusErr = "loading the subtree…", false
+			return m, m.loadSubtree(m.selectedResource(), func(m *Model, tree *models.Resource) tea.Cmd {
+				c, cmd := m.crossplaneTogglePause(tree, true)
+				m.confirm = c
+				return cmd
+			})
+
+		case key.Matches(msg, m.keys.Suspend):
+			m.status, m.statusErr = "", false
+			c, cmd := m.fluxToggleSuspend(m.selectedResource())
+			m.confirm = c
+			return m, cmd
+
+		case key.Matches(msg, m.keys.Delete):
+			r := m.selectedResource()
+			if r != nil {
+				// plan against the ownership tree, the usage tree leaves out Usages
+				if n := m.root.Find(r.ID); n != nil {
+					r = n
 				}
 			}
+			if err := deletable(r); err != nil {
+				return m, actionResult("", err)
+			}
 
-		case "x", "space":
-			if !m.showViewport {
-				selected, ok := m.list.SelectedItem().(models.Resource)
-				if ok {
-					tree := m.root
-					if m.sort == UsageSort && m.usageRoot != nil {
-						tree = m.usageRoot
-					}
-					node := findResourceByID(tree, selected.ID)
-					if node != nil && len(node.Children) > 0 {
-						node.Expanded = !node.Expanded
-						cmd := m.list.SetItems(flatten(*tree, 0))
-						if node.Expanded && !node.ChildrenLoaded {
-							return m, tea.Batch(cmd, func() tea.Msg {
-								return ExpandNodeMsg{Resource: node}
-							})
-						}
-						return m, cmd
+			// plan against the whole tree, Usages of the target are often in
+			// its siblings and policies below collapsed nodes would be missed
+			m.status, m.statusErr = "loading the tree…", false
+			ref := r.Ref
+			return m, m.loadSubtree(m.root, func(m *Model, tree *models.Resource) tea.Cmd {
+				target := findObject(tree, ref)
+				if target == nil {
+					return actionResult("", fmt.Errorf("%s is no longer in the tree", displayName(r)))
+				}
+
+				plan, d, cmd := m.planDeletion(tree, target)
+				if d == nil {
+					return cmd
+				}
+
+				m.deletion = d
+				m.panel.SetContent("Deletion plan", plan.String())
+				m.showPanel = true
+				return cmd
+			})
+
+		case key.Matches(msg, m.keys.Stuck):
+			// scan the whole tree like xrefs diagnose stuck, also below collapsed nodes
+			m.status, m.statusErr = "loading the tree…", false
+			return m, m.loadSubtree(m.root, func(m *Model, tree *models.Resource) tea.Cmd {
+				now := time.Now()
+				stuck := diagnose.StuckDeletions(tree, m.stuckThreshold, now)
+				m.panel.SetContent("Stuck deletions", diagnose.StuckReport(stuck, m.stuckThreshold, now))
+				m.showPanel = true
+				return nil
+			})
+
+		case key.Matches(msg, m.keys.Why):
+			r := m.selectedResource()
+			if r == nil {
+				return m, nil
+			}
+			if n := m.root.Find(r.ID); n != nil {
+				r = n
+			}
+
+			// look for causes below collapsed nodes too, like xrefs diagnose why
+			m.status, m.statusErr = "loading the subtree…", false
+			return m, m.loadSubtree(r, func(m *Model, tree *models.Resource) tea.Cmd {
+				report := diagnose.RootCauseReport(tree, diagnose.RootCauses(tree), time.Now())
+				m.panel.SetContent("Why is "+displayName(tree)+" not ready?", report)
+				m.showPanel = true
+				return nil
+			})
+
+		case key.Matches(msg, m.keys.Timeline):
+			r := m.selectedResource()
+			if r == nil {
+				return m, nil
+			}
+			if n := m.root.Find(r.ID); n != nil {
+				r = n
+			}
+
+			m.panel.SetContent("Condition changes of "+displayName(r), timelineReport(r))
+			m.showPanel = true
+			return m, nil
+
+		case key.Matches(msg, m.keys.Inspect):
+			selected, ok := m.list.SelectedItem().(models.Resource)
+			if ok {
+				m.resourceViewModel.SetResource(&selected)
+				m.showViewport = true
+				return m, nil
+			}
+
+		case key.Matches(msg, m.keys.Toggle):
+			selected, ok := m.list.SelectedItem().(models.Resource)
+			if ok {
+				tree := m.tree()
+				node := tree.Find(selected.ID)
+				if node != nil && len(node.Children) > 0 {
+					node.Expanded = !node.Expanded
+					cmd := m.setItems(m.items(tree))
+					if node.Expanded && !node.ChildrenLoaded {
+						return m, tea.Batch(cmd, func() tea.Msg {
+							return ExpandNodeMsg{Resource: node, Depth: 1}
+						})
 					}
+					return m, cmd
 				}
 			}
-		case "u":
+		case key.Matches(msg, m.keys.Command):
+			if m.showViewport {
+				break
+			}
+			if m.open == nil || m.watch == nil {
+				m.status, m.statusErr = "opening another root is not available", true
+				return m, nil
+			}
+			m.prompt = newPrompt()
+			return m, m.prompt.input.Focus()
+
+		case key.Matches(msg, m.keys.Search):
+			m.search = newSearch()
+			m.search.typing = true
+			return m, m.search.input.Focus()
+
+		case key.Matches(msg, m.keys.NextMatch, m.keys.PrevMatch):
+			if m.search == nil || len(m.search.matches) == 0 {
+				break
+			}
+			step := 1
+			if key.Matches(msg, m.keys.PrevMatch) {
+				step = len(m.search.matches) - 1
+			}
+			m.search.current = (m.search.current + step) % len(m.search.matches)
+			m.jumpToMatch()
+			return m, nil
+
+		case key.Matches(msg, m.keys.Filter):
+			m.healthFilter = m.healthFilter.next()
+			return m, m.setItems(m.items(m.tree()))
+
+		case key.Matches(msg, m.keys.Clear):
+			if m.search != nil && !m.showViewport {
+				m.search = nil
+				return m, m.setSearchMatches(nil)
+			}
+			if m.healthFilter != NoHealthFilter && !m.showViewport {
+				m.healthFilter = NoHealthFilter
+				return m, m.setItems(m.items(m.tree()))
+			}
+
+		case key.Matches(msg, m.keys.Expand, m.keys.Collapse):
+			node := m.selectedResource()
+			if node == nil {
+				return m, nil
+			}
+			return m, m.setExpanded(node, key.Matches(msg, m.keys.Expand))
+
+		case key.Matches(msg, m.keys.ExpandAll, m.keys.CollapseAll):
+			cmd := m.setExpanded(m.tree(), key.Matches(msg, m.keys.ExpandAll))
+			m.tree().Expanded = true // keep the first level visible
+			return m, tea.Batch(cmd, m.setItems(m.items(m.tree())))
+
+		case key.Matches(msg, m.keys.Usage):
 			curIdx := m.list.Index()
 			if m.sort == UsageSort {
 				return m, tea.Batch(
@@ -176,7 +620,9 @@ func (m Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 		var cmd tea.Cmd
 		h, v := docStyle.GetFrameSize()
 		m.resourceViewModel, cmd = m.resourceViewModel.Update(msg)
-		m.list.SetSize(msg.Width-h, msg.Height-v-1)
+		m.panel, _ = m.panel.Update(msg)
+		m.width, m.height = msg.Width-h, msg.Height-v
+		m.resize()
 		return m, cmd
 	}
 
@@ -192,20 +638,25 @@ func (m Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 }
 
 func (m Model) View() tea.View {
+	if m.showPanel {
+		footer := mutedStyle.Render("↑/↓ scroll • " + shortHelp(m.keys.Back, m.keys.Quit))
+		if m.deletion != nil {
+			footer = m.deletion.prompt()
+		}
+
+		v := tea.NewView(docStyle.Render(m.panel.View(footer)))
+		v.AltScreen = true
+		return v
+	}
+
 	if m.showViewport {
+		if m.confirm != nil {
+			m.resourceViewModel.status = confirmStyle.Render(m.confirm.prompt + " (y/N)")
+		}
 		return m.resourceViewModel.View()
 	}
 
-	columns := lipgloss.NewStyle().
-		Foreground(lipgloss.Color("#6f6f6f")).
-		Render(fmt.Sprintf(
-			"%-64s  %-15s %-13s %-14s %s",
-			"RESOURCE",
-			"NAMESPACE",
-			"READY",
-			"SYNCED",
-			"REASON",
-		))
+	columns := mutedStyle.Render(m.delegate.layout.row("RESOURCE", m.delegate.layout.headers()))
 
 	status := "not updated yet"
 	if !m.rootUpdatedAt.IsZero() {
@@ -214,16 +665,84 @@ func (m Model) View() tea.View {
 	if m.sort == UsageSort {
 		status = "usage view (static snapshot)"
 	}
+	if !m.rootDeletedAt.IsZero() {
+		status = fmt.Sprintf("root deleted %s ago", since(m.rootDeletedAt))
+		if m.followDeletion {
+			status += fmt.Sprintf(", %d resources remaining", m.root.Remaining())
+		}
+	}
+	if m.deletionDone {
+		status = "all resources deleted"
+	}
+	if m.healthFilter != NoHealthFilter {
+		status = fmt.Sprintf("showing %s resources (%s to clear) • %s", m.healthFilter, m.keys.Clear.Help().Key, status)
+	}
 
-	footer := lipgloss.NewStyle().
-		Foreground(lipgloss.Color("#6f6f6f")).
-		Render("↑/↓ navigate • y inspect • u toggle usage • ctrl+c quit • " + status)
+	footer := mutedStyle.Render("↑/↓ navigate • " + shortHelp(
+		m.keys.Inspect,
+		m.keys.Toggle,
+		m.keys.Search,
+		m.keys.Filter,
+		m.keys.Why,
+		m.keys.Focus,
+		m.keys.Help,
+		m.keys.Quit,
+	) + " • " + status)
+
+	if m.search != nil {
+		if m.search.typing {
+			footer = m.search.input.View()
+		} else {
+			footer = confirmStyle.Render(m.search.status(m.keys))
+		}
+	}
+
+	if m.prompt != nil {
+		footer = m.prompt.view(m.list.Width())
+	}
 
-	body := strings.Join([]string{
-		columns,
-		m.list.View(),
-		footer,
-	}, "\n")
+	if m.confirm != nil {
+		footer = confirmStyle.Render(m.confirm.prompt + " (y/N)")
+	}
+
+	// make room for the status below the footer
+	l := m.list
+	statusLine := ""
+	if m.status != "" {
+		style := successStyle
+		if m.statusErr {
+			style = errorStyle
+		}
+		statusLine = style.Width(l.Width()).Render(m.status)
+		l.SetHeight(max(l.Height()-lipgloss.Height(statusLine), 1))
+	}
+	if h := lipgloss.Height(footer); h > 1 {
+		l.SetHeight(max(l.Height()-h+1, 1))
+	}
+
+	var lines []string
+	if len(m.crumbs) > 0 {
+		// make room for the breadcrumb above the columns
+		l.SetHeight(max(l.Height()-1, 1))
+		lines = append(lines, m.breadcrumb())
+	}
+
+	tree := columns + "\n" + l.View()
+	if m.split {
+		height := lipgloss.Height(tree)
+		separator := mutedStyle.Render(strings.TrimSuffix(strings.Repeat("│\n", height), "\n"))
+		tree = lipgloss.JoinHorizontal(lipgloss.Top,
+			lipgloss.NewStyle().Width(l.Width()).Render(tree),
+			separator,
+			m.details.View(m.width-l.Width()-1, height),
+		)
+	}
+	lines = append(lines, tree, footer)
+	if statusLine != "" {
+		lines = append(lines, statusLine)
+	}
+
+	body := strings.Join(lines, "\n")
 
 	v := tea.NewView(docStyle.Render(body))
 	v.AltScreen = true
@@ -231,18 +750,32 @@ func (m Model) View() tea.View {
 }
 
 type resourceDelegate struct {
-	selected lipgloss.Style
-	normal   lipgloss.Style
-	notFound lipgloss.Style
-	err      lipgloss.Style
+	selected  lipgloss.Style
+	normal    lipgloss.Style
+	notFound  lipgloss.Style
+	err       lipgloss.Style
+	suspended lipgloss.Style // suspended or paused, i.e. not reconciled
+	healthy   lipgloss.Style
+	unknown   lipgloss.Style
+	match     lipgloss.Style
+	changed   lipgloss.Style
+
+	columns []config.Column // configured columns, of which layout has those that fit
+	layout  layout
+	matches map[string]bool // IDs of search matches
 }
 
 func NewResourceDelegate() resourceDelegate {
 	return resourceDelegate{
-		selected: lipgloss.NewStyle().Foreground(lipgloss.Color("#ffffff")).Bold(true),
-		normal:   lipgloss.NewStyle().Foreground(lipgloss.Color("#9b9b9b")),
-		notFound: lipgloss.NewStyle().Foreground(lipgloss.Color("#ff9898")),
-		err:      lipgloss.NewStyle().Foreground(lipgloss.Color("#ff5f5f")),
+		selected:  selectedStyle(),
+		normal:    foreground(theme.Foreground),
+		notFound:  foreground(theme.NotFound),
+		err:       foreground(theme.Error),
+		suspended: foreground(theme.Suspended),
+		healthy:   foreground(theme.Success),
+		unknown:   foreground(theme.Warning),
+		match:     foreground(theme.Info).Underline(true),
+		changed:   foreground(theme.Warning).Bold(true),
 	}
 }
 
@@ -255,53 +788,164 @@ func (d resourceDelegate) Update(msg tea.Msg, m *list.Model) tea.Cmd {
 func (d resourceDelegate) Render(w io.Writer, m list.Model, index int, item list.Item) {
 	r := item.(models.Resource)
 
-	ready := condStatus(r, "Ready")
-	synced := condStatus(r, "Synced")
-	reason := shorten(condReason(r), 40)
-
-	if r.Error != nil {
-		ready = "-"
-		synced = "-"
-		reason = shorten(r.Error.Error(), 40)
+	cells := make([]string, 0, len(d.layout.columns))
+	for _, c := range d.layout.columns {
+		cells = append(cells, cell(c, r))
 	}
 
-	if r.NotFound {
-		ready = "-"
-		synced = "-"
-		reason = "Resource was not found"
-	}
-
-	row := fmt.Sprintf(
-		"%-64s  %-15s %-13s %-14s %s",
-		treeName(r),
-		namespace(r),
-		ready,
-		synced,
-		reason,
-	)
+	rollup := r.Rollup()
+	row := d.layout.row(treeName(r), cells)
 
 	isSelected := index == m.Index()
 
+	style := d.normal
 	switch {
+	case r.Unstructured != nil && r.Unstructured.GetDeletionTimestamp() != nil && !r.NotFound && !isSelected:
+		style = d.err
+
 	case r.NotFound && isSelected:
-		fmt.Fprint(w, d.notFound.Bold(true).Render(row))
+		style = d.notFound.Bold(true)
 
 	case r.NotFound:
-		fmt.Fprint(w, d.notFound.Render(row))
+		style = d.notFound
 
 	case isSelected:
-		fmt.Fprint(w, d.selected.Render(row))
+		style = d.selected
+
+	case d.matches[r.ID]:
+		style = d.match
+
+	case recentlyChanged(r, time.Now()):
+		style = d.changed
+
+	case k8s.FluxSuspended(r.Unstructured), k8s.CrossplanePaused(r.Unstructured):
+		style = d.suspended
+	}
+
+	// colour the expand marker by the worst health below the node, so a
+	// collapsed parent shows when a descendant is failing
+	marker := strings.IndexAny(row, "▶▼")
+	if rollup.Total == 0 || marker < 0 {
+		fmt.Fprint(w, style.Render(row))
+		return
+	}
 
+	end := marker + len("▶")
+	fmt.Fprint(w,
+		style.Render(row[:marker])+
+			d.marker(rollup.Worst).Inherit(style).Render(row[marker:end])+
+			style.Render(row[end:]),
+	)
+}
+
+// marker returns the style of the expand marker for the worst severity
+// among the descendants of a node.
+func (d resourceDelegate) marker(worst models.Severity) lipgloss.Style {
+	switch worst {
+	case models.SeverityHealthy:
+		return d.healthy
+	case models.SeverityUnknown:
+		return d.unknown
 	default:
-		fmt.Fprint(w, d.normal.Render(row))
+		return d.err
+	}
+}
+
+// cell renders the value of column c for r.
+func cell(c config.Column, r models.Resource) string {
+	gone := r.Error != nil || r.NotFound
+
+	switch c.Type {
+	case config.ColumnNamespace:
+		return namespace(r)
+
+	case config.ColumnReady:
+		if gone {
+			return "-"
+		}
+		return condStatus(r, "Ready")
+
+	case config.ColumnSynced:
+		if gone {
+			return "-"
+		}
+		return condStatus(r, "Synced")
+
+	case config.ColumnCondition:
+		if gone {
+			return "-"
+		}
+		return condStatus(r, c.Condition)
+
+	case config.ColumnHealth:
+		if rollup := r.Rollup(); rollup.Total+rollup.NotLoaded > 0 {
+			return rollup.String()
+		}
+		return "-"
+
+	case config.ColumnReason:
+		return reason(r)
+
+	case config.ColumnAge:
+		if r.Unstructured == nil {
+			return "-"
+		}
+		created := r.Unstructured.GetCreationTimestamp()
+		if created.IsZero() {
+			return "-"
+		}
+		return since(created.Time)
+
+	case config.ColumnJSONPath:
+		if r.Unstructured == nil {
+			return "-"
+		}
+		if v := c.Lookup(r.Unstructured.Object); v != "" {
+			return v
+		}
 	}
+
+	return "-"
+}
+
+// reason explains the state of r: the reason of its conditions, its error
+// or the progress of its deletion.
+func reason(r models.Resource) string {
+	reason := condReason(r)
+
+	if r.Error != nil {
+		reason = r.Error.Error()
+	}
+
+	if r.NotFound {
+		reason = "Resource was not found"
+		if r.Unstructured != nil && r.Unstructured.GetDeletionTimestamp() != nil {
+			reason = "Deleted"
+		}
+	} else if d := deletionStatus(r); d != "" {
+		reason = d
+	}
+
+	if b := badges(r); b != "" {
+		reason = b + " " + reason
+	}
+
+	return reason
 }
 
 func flatten(r models.Resource, depth int) []list.Item {
-	return flattenWithPrefix(r, depth, true, "")
+	return flattenWithPrefix(r, depth, true, "", expandedChildren)
 }
 
-func flattenWithPrefix(r models.Resource, depth int, isLast bool, prefix string) []list.Item {
+// expandedChildren returns the children shown below r in the unfiltered tree.
+func expandedChildren(r models.Resource) []models.Resource {
+	if !r.Expanded {
+		return nil
+	}
+	return r.Children
+}
+
+func flattenWithPrefix(r models.Resource, depth int, isLast bool, prefix string, children func(models.Resource) []models.Resource) []list.Item {
 	r.Depth = depth
 	r.IsLast = isLast
 	r.Prefix = prefix
@@ -317,33 +961,97 @@ func flattenWithPrefix(r models.Resource, depth int, isLast bool, prefix string)
 		}
 	}
 
-	if !r.Expanded {
-		return out
-	}
-
-	for i, child := range r.Children {
+	visible := children(r)
+	for i, child := range visible {
 		out = append(out, flattenWithPrefix(
 			child,
 			depth+1,
-			i == len(r.Children)-1,
+			i == len(visible)-1,
 			childPrefix,
+			children,
 		)...)
 	}
 
 	return out
 }
 
-// findResourceByID traverses the resource tree and returns a pointer to the node with the given ID.
-func findResourceByID(r *models.Resource, id string) *models.Resource {
-	if r.ID == id {
-		return r
+// setItems replaces the rows of the list and sizes the columns to them.
+func (m *Model) setItems(items []list.Item) tea.Cmd {
+	cmd := m.list.SetItems(items)
+	m.relayout()
+	return cmd
+}
+
+// resize splits the window between the tree and the details pane.
+func (m *Model) resize() {
+	width := m.width
+	if m.split {
+		width = m.width / 2
+		m.details.viewport.SetWidth(m.width - width - 1)
+		m.details.viewport.SetHeight(m.height - 1)
 	}
-	for i := range r.Children {
-		if found := findResourceByID(&r.Children[i], id); found != nil {
-			return found
+
+	m.list.SetSize(width, m.height-1)
+	m.relayout()
+}
+
+// relayout sizes the columns to the rows and the width of the list.
+func (m *Model) relayout() {
+	m.delegate.layout = newLayout(m.delegate.columns, m.list.Items(), m.list.Width())
+	m.list.SetDelegate(m.delegate)
+}
+
+// setExpanded expands or collapses node and all of its descendants. Expanding
+// also requests the rest of the subtree to be loaded, since the children of
+// nodes that were collapsed are not known yet.
+func (m *Model) setExpanded(node *models.Resource, expanded bool) tea.Cmd {
+	node.Walk(func(r *models.Resource) {
+		if len(r.Children) > 0 {
+			r.Expanded = expanded
 		}
+	})
+
+	cmd := m.setItems(m.items(m.tree()))
+	if !expanded {
+		return cmd
 	}
-	return nil
+
+	return tea.Batch(cmd, func() tea.Msg {
+		return ExpandNodeMsg{Resource: node, Depth: -1}
+	})
+}
+
+// tree returns the tree shown in the list.
+func (m Model) tree() *models.Resource {
+	if m.sort == UsageSort && m.usageRoot != nil {
+		return m.usageRoot
+	}
+	return m.root
+}
+
+// items flattens the tree into list items, applying the health filter.
+func (m Model) items(tree *models.Resource) []list.Item {
+	if m.healthFilter != NoHealthFilter {
+		return flattenFiltered(*tree, m.healthFilter)
+	}
+	return flatten(*tree, 0)
+}
+
+// selectedResource returns the resource shown in the viewer, or the resource
+// selected in the list when the viewer is closed.
+func (m Model) selectedResource() *models.Resource {
+	tree := m.tree()
+
+	if m.showViewport {
+		return tree.Find(m.resourceViewModel.resourceID)
+	}
+
+	selected, ok := m.list.SelectedItem().(models.Resource)
+	if !ok {
+		return nil
+	}
+
+	return tree.Find(selected.ID)
 }
 
 func treeName(r models.Resource) string {
@@ -378,12 +1086,6 @@ func treeName(r models.Resource) string {
 		}
 	}
 
-	const maxCol = 64
-	maxLabel := maxCol - len(prefix)
-	if maxLabel > 0 && len(label) > maxLabel {
-		label = label[:maxLabel-1] + "…"
-	}
-
 	return prefix + label
 }
 
@@ -415,11 +1117,34 @@ func condReason(r models.Resource) string {
 	return "-"
 }
 
-func shorten(s string, max int) string {
-	if len(s) <= max {
-		return s
+// deletionStatus describes the progress of a resource that is being deleted:
+// how long the deletion has been going on and the finalizers left.
+func deletionStatus(r models.Resource) string {
+	if r.Unstructured == nil || r.Unstructured.GetDeletionTimestamp() == nil {
+		return ""
+	}
+
+	s := "Deleting " + since(r.Unstructured.GetDeletionTimestamp().Time)
+	if f := r.Unstructured.GetFinalizers(); len(f) > 0 {
+		s += " • finalizers: " + strings.Join(f, ", ")
+	}
+	return s
+}
+
+// since formats the time passed since t like kubectl formats ages.
+func since(t time.Time) string {
+	return duration.HumanDuration(time.Since(t))
+}
+
+// badges returns markers for resource states worth calling out in the row,
+// like a suspended Flux resource or a paused Crossplane resource.
+func badges(r models.Resource) string {
+	var b []string
+	if k8s.FluxSuspended(r.Unstructured) {
+		b = append(b, "SUSPENDED")
+	}
+	if k8s.CrossplanePaused(r.Unstructured) {
+		b = append(b, "PAUSED")
 	}
-	head := max / 2
-	tail := max - head - 1
-	return s[:head] + "(...)" + s[len(s)-tail:]
+	return strings.Join(b, " ")
 }
@@ -0,0 +1,178 @@
+package ui
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"charm.land/bubbles/v2/key"
+	"charm.land/bubbles/v2/textinput"
+	tea "charm.land/bubbletea/v2"
+	"github.com/nkzk/xrefs/internal/models"
+	"github.com/sahilm/fuzzy"
+)
+
+// search is a fuzzy search across the whole loaded tree, including the
+// children of collapsed nodes.
+type search struct {
+	input  textinput.Model
+	typing bool
+
+	query   string
+	matches []string // IDs of the matching nodes, in tree order
+	current int
+}
+
+func newSearch() *search {
+	s := &search{input: textinput.New()}
+	s.input.Prompt = "/"
+	s.input.Placeholder = "kind, name, namespace, label or reason"
+	return s
+}
+
+// searchFields are what a node is matched against. Fields are matched one
+// by one, so a query does not match letters scattered across fields.
+func searchFields(r *models.Resource) []string {
+	parts := []string{displayName(r), namespace(*r)}
+
+	if r.Unstructured != nil {
+		labels := r.Unstructured.GetLabels()
+		keys := make([]string, 0, len(labels))
+		for k := range labels {
+			keys = append(keys, k)
+		}
+		sort.Strings(keys)
+		for _, k := range keys {
+			parts = append(parts, k+"="+labels[k])
+		}
+	}
+
+	if reason := condReason(*r); reason != "-" {
+		parts = append(parts, reason)
+	}
+
+	return parts
+}
+
+// searchTree returns the IDs of the nodes matching query, in tree order.
+func searchTree(tree *models.Resource, query string) []string {
+	var ids []string
+	tree.Walk(func(r *models.Resource) {
+		if len(fuzzy.Find(query, searchFields(r))) > 0 {
+			ids = append(ids, r.ID)
+		}
+	})
+	return ids
+}
+
+// expandAncestors expands every node on the path to the nodes with the
+// given IDs, so they are visible in the list, and returns the nodes it
+// expanded.
+func expandAncestors(tree *models.Resource, ids []string) []*models.Resource {
+	want := make(map[string]bool, len(ids))
+	for _, id := range ids {
+		want[id] = true
+	}
+
+	var expanded []*models.Resource
+	var visit func(r *models.Resource) bool
+	visit = func(r *models.Resource) bool {
+		below := false
+		for i := range r.Children {
+			if visit(&r.Children[i]) {
+				below = true
+			}
+		}
+		if below && !r.Expanded {
+			r.Expanded = true
+			expanded = append(expanded, r)
+		}
+		return below || want[r.ID]
+	}
+	visit(tree)
+
+	return expanded
+}
+
+// updateSearch handles key presses while the search query is typed.
+func (m Model) updateSearch(msg tea.KeyPressMsg) (Model, tea.Cmd) {
+	s := m.search
+
+	if key.Matches(msg, m.keys.Quit) {
+		return m, tea.Quit
+	}
+
+	switch msg.String() {
+	case "esc":
+		m.search = nil
+		return m, m.setSearchMatches(nil)
+
+	case "enter":
+		s.typing = false
+		s.input.Blur()
+		s.query = strings.TrimSpace(s.input.Value())
+		if s.query == "" {
+			m.search = nil
+			return m, m.setSearchMatches(nil)
+		}
+
+		s.matches = searchTree(m.tree(), s.query)
+		s.current = 0
+		cmds := []tea.Cmd{m.setSearchMatches(s.matches)}
+		// load the children of the nodes expanded to show the matches, like
+		// the expand key does
+		for _, node := range expandAncestors(m.tree(), s.matches) {
+			if !node.ChildrenLoaded {
+				cmds = append(cmds, func() tea.Msg {
+					return ExpandNodeMsg{Resource: node, Depth: 1}
+				})
+			}
+		}
+		m.jumpToMatch()
+		return m, tea.Batch(cmds...)
+	}
+
+	var cmd tea.Cmd
+	s.input, cmd = s.input.Update(msg)
+	return m, cmd
+}
+
+// setSearchMatches highlights the matching nodes and refreshes the list,
+// which may have gained rows from expanded ancestors.
+func (m *Model) setSearchMatches(ids []string) tea.Cmd {
+	m.delegate.matches = nil
+	if len(ids) > 0 {
+		m.delegate.matches = make(map[string]bool, len(ids))
+		for _, id := range ids {
+			m.delegate.matches[id] = true
+		}
+	}
+	m.list.SetDelegate(m.delegate)
+	return m.setItems(m.items(m.tree()))
+}
+
+// jumpToMatch selects the current match in the list, if it is visible.
+func (m *Model) jumpToMatch() {
+	s := m.search
+	if s == nil || len(s.matches) == 0 {
+		return
+	}
+
+	id := s.matches[s.current]
+	for i, item := range m.list.Items() {
+		if r, ok := item.(models.Resource); ok && r.ID == id {
+			m.list.Select(i)
+			return
+		}
+	}
+}
+
+// status describes the search for the footer.
+func (s *search) status(keys *keyMap) string {
+	clear := keys.Clear.Help().Key
+	if len(s.matches) == 0 {
+		return fmt.Sprintf("no matches for %q (%s to clear)", s.query, clear)
+	}
+	return fmt.Sprintf("match %d/%d for %q • %s (%s to clear)",
+		s.current+1, len(s.matches), s.query, shortHelp(keys.NextMatch, keys.PrevMatch), clear)
+}
@@ -0,0 +1,162 @@
+package ui
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+
+	"charm.land/bubbles/v2/key"
+	tea "charm.land/bubbletea/v2"
+	"github.com/charmbracelet/x/ansi"
+)
+
+// Tabs shows several roots, each in a tree of its own fed by its own
+// producer, of which one is shown at a time.
+type Tabs struct {
+	tabs   []Model
+	active int
+	keys   *keyMap
+	width  int
+}
+
+// TabMsg carries a message of the producer of a tab, so it reaches the tree
+// of that tab rather than the one shown. Other messages go to the tab shown.
+type TabMsg struct {
+	Tab int
+	Msg tea.Msg
+}
+
+// NewTabs shows the trees in tabs, the first one selected.
+func NewTabs(trees []*Model) Tabs {
+	tabs := make([]Model, 0, len(trees))
+	for _, t := range trees {
+		tabs = append(tabs, *t)
+	}
+
+	return Tabs{
+		tabs: tabs,
+		keys: tabs[0].keys,
+	}
+}
+
+func (t Tabs) Init() tea.Cmd { return nil }
+
+func (t Tabs) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
+	switch msg := msg.(type) {
+	case TabMsg:
+		if msg.Tab < 0 || msg.Tab >= len(t.tabs) {
+			return t, nil
+		}
+		cmd := t.updateTab(msg.Tab, msg.Msg)
+		return t, tagged(msg.Tab, cmd)
+
+	case tea.WindowSizeMsg:
+		h, _ := docStyle.GetFrameSize()
+		t.width = msg.Width - h
+
+		// the tab bar takes the place of the top margin of the trees
+		cmds := make([]tea.Cmd, 0, len(t.tabs))
+		for i := range t.tabs {
+			cmds = append(cmds, tagged(i, t.updateTab(i, msg)))
+		}
+		return t, tea.Batch(cmds...)
+
+	case tea.KeyPressMsg:
+		if t.tabs[t.active].typing() {
+			break
+		}
+
+		switch {
+		case key.Matches(msg, t.keys.NextTab):
+			t.active = (t.active + 1) % len(t.tabs)
+			return t, nil
+
+		case key.Matches(msg, t.keys.PrevTab):
+			t.active = (t.active + len(t.tabs) - 1) % len(t.tabs)
+			return t, nil
+
+		case len(msg.String()) == 1 && msg.String() >= "1" && msg.String() <= "9":
+			if i := int(msg.String()[0] - '1'); i < len(t.tabs) {
+				t.active = i
+			}
+			return t, nil
+		}
+	}
+
+	// the results of keys, like a root opened or an action, belong to the tab
+	// they were pressed in, also when another tab is shown by then
+	return t, tagged(t.active, t.updateTab(t.active, msg))
+}
+
+// updateTab passes msg to the tree of tab i.
+func (t Tabs) updateTab(i int, msg tea.Msg) tea.Cmd {
+	m, cmd := t.tabs[i].Update(msg)
+	t.tabs[i] = m.(Model)
+	return cmd
+}
+
+// tagged routes the messages of cmd to tab i, for the commands of a tab that
+// is not necessarily shown, like the refresh of its rows after an update.
+// Messages of bubbletea itself, like quitting, running the editor or setting
+// the clipboard, are passed on for the program to handle.
+func tagged(i int, cmd tea.Cmd) tea.Cmd {
+	if cmd == nil {
+		return nil
+	}
+
+	return func() tea.Msg {
+		switch msg := cmd().(type) {
+		case nil:
+			return nil
+		case tea.BatchMsg:
+			cmds := make(tea.BatchMsg, 0, len(msg))
+			for _, c := range msg {
+				cmds = append(cmds, tagged(i, c))
+			}
+			return cmds
+		default:
+			if reflect.TypeOf(msg).PkgPath() == teaPkg {
+				return msg
+			}
+			return TabMsg{Tab: i, Msg: msg}
+		}
+	}
+}
+
+// teaPkg is the package of the messages bubbletea handles itself.
+var teaPkg = reflect.TypeOf(tea.QuitMsg{}).PkgPath()
+
+// typing reports whether the keys of m go to an input or a confirmation,
+// so they are not taken to switch tabs.
+func (m Model) typing() bool {
+	return m.deletion != nil || m.confirm != nil || m.prompt != nil ||
+		(m.search != nil && m.search.typing)
+}
+
+// bar renders a tab per root, marked by the worst health of its tree.
+func (t Tabs) bar() string {
+	d := t.tabs[0].delegate
+
+	labels := make([]string, 0, len(t.tabs))
+	for i, tab := range t.tabs {
+		label := fmt.Sprintf(" %d %s ", i+1, displayName(tab.root))
+
+		style := mutedStyle
+		if i == t.active {
+			style = selectedStyle()
+		}
+		labels = append(labels, d.marker(worst(tab.root)).Render("●")+style.Render(label))
+	}
+
+	return ansi.Truncate(strings.Join(labels, " "), max(t.width, 1), "…")
+}
+
+func (t Tabs) View() tea.View {
+	v := t.tabs[t.active].View()
+
+	_, body, _ := strings.Cut(v.Content, "\n")
+	margin := strings.Repeat(" ", docStyle.GetMarginLeft())
+	v.Content = margin + t.bar() + "\n" + body
+
+	return v
+}
@@ -0,0 +1,69 @@
+package ui
+
+import (
+	lipgloss "charm.land/lipgloss/v2"
+	"github.com/nkzk/xrefs/internal/config"
+)
+
+// The styles of the tui, derived from the theme by setTheme.
+var (
+	docStyle = lipgloss.NewStyle().Margin(1, 2)
+
+	mutedStyle      lipgloss.Style
+	confirmStyle    lipgloss.Style
+	successStyle    lipgloss.Style
+	errorStyle      lipgloss.Style
+	panelTitleStyle lipgloss.Style
+	diffAddStyle    lipgloss.Style
+	diffDelStyle    lipgloss.Style
+	diffHunkStyle   lipgloss.Style
+	changedStyle    lipgloss.Style
+
+	// syntaxStyle is the chroma style highlighting YAML, empty for none.
+	syntaxStyle string
+
+	theme config.Theme
+)
+
+func init() {
+	setTheme(config.DarkTheme)
+}
+
+// setTheme derives the styles of the tui from t.
+func setTheme(t config.Theme) {
+	theme = t
+
+	mutedStyle = foreground(t.Muted)
+	confirmStyle = foreground(t.Warning).Bold(true)
+	successStyle = foreground(t.Success)
+	errorStyle = foreground(t.Error)
+	panelTitleStyle = foreground(t.Title).Bold(true)
+	diffAddStyle = foreground(t.Success)
+	diffDelStyle = foreground(t.Error)
+	diffHunkStyle = foreground(t.Info)
+	changedStyle = foreground(t.Success)
+	syntaxStyle = t.Syntax
+}
+
+// foreground returns a style with the colour c, or the terminal default
+// when c is empty.
+func foreground(c string) lipgloss.Style {
+	if c == "" {
+		return lipgloss.NewStyle()
+	}
+	return lipgloss.NewStyle().Foreground(lipgloss.Color(c))
+}
+
+// selectedStyle is the style of the selected row. Without colours the row
+// is shown in reverse video, so the selection stays visible.
+func selectedStyle() lipgloss.Style {
+	if theme.Selected == "" && theme.SelectedBackground == "" {
+		return lipgloss.NewStyle().Bold(true).Reverse(true)
+	}
+
+	style := foreground(theme.Selected).Bold(true)
+	if theme.SelectedBackground != "" {
+		style = style.Background(lipgloss.Color(theme.SelectedBackground))
+	}
+	return style
+}
@@ -0,0 +1,84 @@
+package ui
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	tea "charm.land/bubbletea/v2"
+	"github.com/nkzk/xrefs/internal/models"
+)
+
+// highlightDuration is how long a row stays highlighted after its conditions
+// changed.
+const highlightDuration = 5 * time.Second
+
+// clearHighlightMsg re-renders the tree once highlights have expired.
+type clearHighlightMsg struct{}
+
+// recentlyChanged reports whether the conditions of r changed within the
+// highlight duration.
+func recentlyChanged(r models.Resource, now time.Time) bool {
+	return !r.ChangedAt.IsZero() && now.Sub(r.ChangedAt) < highlightDuration
+}
+
+// clearHighlightAfter schedules a re-render for when the rows highlighted in
+// the tree of root expire.
+func clearHighlightAfter(root *models.Resource) tea.Cmd {
+	now := time.Now()
+
+	changed := false
+	root.Walk(func(r *models.Resource) {
+		changed = changed || recentlyChanged(*r, now)
+	})
+	if !changed {
+		return nil
+	}
+
+	return tea.Tick(highlightDuration, func(time.Time) tea.Msg {
+		return clearHighlightMsg{}
+	})
+}
+
+// timelineReport renders the condition transitions of r, newest first.
+func timelineReport(r *models.Resource) string {
+	if len(r.Transitions) == 0 {
+		return mutedStyle.Render("No condition changes observed since xrefs started.")
+	}
+
+	var b strings.Builder
+	for i := len(r.Transitions) - 1; i >= 0; i-- {
+		b.WriteString(transitionLine(r.Transitions[i]) + "\n")
+		if msg := r.Transitions[i].Message; msg != "" {
+			b.WriteString(mutedStyle.Render("          "+msg) + "\n")
+		}
+	}
+
+	return b.String()
+}
+
+// transitionLine renders a transition like `15:04:05  Ready  True → False  ReconcileError`.
+func transitionLine(t models.Transition) string {
+	return fmt.Sprintf("%s  %-12s %s → %s  %s",
+		mutedStyle.Render(t.At.Format("15:04:05")),
+		t.Type,
+		conditionStatus(t.From),
+		conditionStatus(t.To),
+		t.Reason,
+	)
+}
+
+// conditionStatus colours a condition status, with a placeholder for a
+// condition that did not exist.
+func conditionStatus(status string) string {
+	switch status {
+	case "True":
+		return successStyle.Render(status)
+	case "False":
+		return errorStyle.Render(status)
+	case "":
+		return mutedStyle.Render("-")
+	default:
+		return status
+	}
+}
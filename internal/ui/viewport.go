@@ -6,12 +6,14 @@ import (
 	"strings"
 	"time"
 
+	"charm.land/bubbles/v2/key"
 	"charm.land/bubbles/v2/viewport"
 	tea "charm.land/bubbletea/v2"
 	"charm.land/lipgloss/v2"
 	"github.com/alecthomas/chroma/formatters"
 	"github.com/alecthomas/chroma/lexers"
 	"github.com/alecthomas/chroma/styles"
+	"github.com/nkzk/xrefs/internal/diff"
 	"github.com/nkzk/xrefs/internal/models"
 	"go.yaml.in/yaml/v2"
 	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
@@ -23,13 +25,31 @@ type resourceViewModel struct {
 	width    int
 	height   int
 
+	// resourceID is the ID of the resource being viewed, so the viewer can
+	// follow the resource as the producer refreshes the tree.
+	resourceID string
+	updatedAt  time.Time
+	changed    int
+
+	changedLines []bool
+
+	// revisions of the resource, and the resourceVersions of the pair being
+	// compared in diff mode
+	history  []models.Revision
+	diffMode bool
+	from     string
+	to       string
+
 	rawYAML string
 	status  string
+
+	keys *keyMap
 }
 
-func newResourceViewModel() resourceViewModel {
+func newResourceViewModel(keys *keyMap) resourceViewModel {
 	return resourceViewModel{
 		viewport: viewport.New(),
+		keys:     keys,
 	}
 }
 
@@ -60,15 +80,50 @@ func (m resourceViewModel) Update(msg tea.Msg) (resourceViewModel, tea.Cmd) {
 		m.ready = true
 
 	case tea.KeyPressMsg:
-		switch msg.String() {
-		case "g":
+		switch {
+		case key.Matches(msg, m.keys.Top):
 			m.viewport.GotoTop()
 			return m, nil
 
-		case "G":
+		case key.Matches(msg, m.keys.Bottom):
 			m.viewport.GotoBottom()
 			return m, nil
-		case "c":
+
+		case key.Matches(msg, m.keys.Diff):
+			if len(m.history) < 2 {
+				m.status = "only one revision observed"
+				return m, clearStatusAfter(1500 * time.Millisecond)
+			}
+
+			m.diffMode = !m.diffMode
+			m.from = m.history[len(m.history)-2].ResourceVersion
+			m.to = m.history[len(m.history)-1].ResourceVersion
+			m.render()
+			m.viewport.GotoTop()
+			return m, nil
+
+		case key.Matches(msg, m.keys.FromOlder, m.keys.FromNewer, m.keys.ToOlder, m.keys.ToNewer):
+			if !m.diffMode {
+				break
+			}
+
+			from, to := m.revision(m.from), m.revision(m.to)
+			switch {
+			case key.Matches(msg, m.keys.FromOlder):
+				from = max(from-1, 0)
+			case key.Matches(msg, m.keys.FromNewer):
+				from = min(from+1, len(m.history)-1)
+			case key.Matches(msg, m.keys.ToOlder):
+				to = max(to-1, 0)
+			case key.Matches(msg, m.keys.ToNewer):
+				to = min(to+1, len(m.history)-1)
+			}
+			m.from, m.to = m.history[from].ResourceVersion, m.history[to].ResourceVersion
+
+			m.render()
+			return m, nil
+
+		case key.Matches(msg, m.keys.Copy):
 			if m.rawYAML == "" {
 				m.status = "nothing to copy"
 				return m, clearStatusAfter(1500 * time.Millisecond)
@@ -90,15 +145,26 @@ func (m resourceViewModel) Update(msg tea.Msg) (resourceViewModel, tea.Cmd) {
 }
 
 func (m resourceViewModel) View() tea.View {
-	footerText := "g top • G bottom • c copy YAML • q back • ctrl+c, q quit"
+	footerText := shortHelp(m.keys.Top, m.keys.Bottom, m.keys.Copy, m.keys.Diff, m.keys.Edit, m.keys.Help, m.keys.Back)
+
+	if m.diffMode {
+		footerText = fmt.Sprintf(
+			"%s • %s yaml • %s • comparing %s → %s",
+			shortHelp(m.keys.FromOlder, m.keys.FromNewer, m.keys.ToOlder, m.keys.ToNewer),
+			m.keys.Diff.Help().Key,
+			shortHelp(m.keys.Back),
+			revisionName(m.history[m.revision(m.from)]),
+			revisionName(m.history[m.revision(m.to)]),
+		)
+	} else if !m.updatedAt.IsZero() {
+		footerText += fmt.Sprintf(" • updated %s (%d lines changed)", m.updatedAt.Format("15:04:05"), m.changed)
+	}
 
 	if m.status != "" {
 		footerText += " • " + m.status
 	}
 
-	footer := lipgloss.NewStyle().
-		Foreground(lipgloss.Color("#6f6f6f")).
-		Render(footerText)
+	footer := mutedStyle.Render(footerText)
 
 	body := strings.Join([]string{
 		m.viewport.View(),
@@ -109,7 +175,16 @@ func (m resourceViewModel) View() tea.View {
 	v.AltScreen = true
 	return v
 }
+
+// SetResource binds the viewer to r and renders it from the top.
 func (m *resourceViewModel) SetResource(r *models.Resource) {
+	m.resourceID = r.ID
+	m.history = r.History
+	m.diffMode = false
+	m.updatedAt = time.Time{}
+	m.changed = 0
+	m.changedLines = nil
+
 	y, err := toYAML(r.Unstructured)
 	if err != nil {
 		m.rawYAML = ""
@@ -118,10 +193,136 @@ func (m *resourceViewModel) SetResource(r *models.Resource) {
 	}
 
 	m.rawYAML = y
-	m.viewport.SetContent(highlightYAML(y))
+	m.render()
 	m.viewport.GotoTop()
 }
 
+// Refresh re-renders the viewer if r is the bound resource and its object has
+// changed, keeping the scroll position and marking the lines that changed
+// since the previous version.
+func (m *resourceViewModel) Refresh(r *models.Resource) {
+	if r == nil || r.ID != m.resourceID {
+		return
+	}
+
+	// keep comparing against the latest revision if that is what was shown
+	if n := len(m.history); n > 0 && m.to == m.history[n-1].ResourceVersion && len(r.History) > 0 {
+		m.to = r.History[len(r.History)-1].ResourceVersion
+	}
+	m.history = r.History
+
+	y, err := toYAML(r.Unstructured)
+	if err != nil || y == m.rawYAML {
+		return
+	}
+
+	changed := diff.Changed(strings.Split(m.rawYAML, "\n"), strings.Split(y, "\n"))
+
+	m.changed = 0
+	for _, c := range changed {
+		if c {
+			m.changed++
+		}
+	}
+
+	offset := m.viewport.YOffset()
+
+	m.rawYAML = y
+	m.changedLines = changed
+	m.updatedAt = time.Now()
+	m.render()
+	m.viewport.SetYOffset(offset)
+}
+
+// render sets the viewport content to either the YAML of the resource or the
+// diff between the selected revisions.
+func (m *resourceViewModel) render() {
+	if m.diffMode {
+		m.viewport.SetContentLines(m.revisionDiff())
+		return
+	}
+
+	if m.changedLines == nil {
+		m.viewport.SetContent(highlightYAML(m.rawYAML))
+		return
+	}
+
+	m.viewport.SetContentLines(markChanged(highlightYAML(m.rawYAML), m.changedLines))
+}
+
+// revisionDiff renders a coloured unified diff between the from and to revisions.
+func (m resourceViewModel) revisionDiff() []string {
+	from, to := m.history[m.revision(m.from)], m.history[m.revision(m.to)]
+
+	a, err := toYAML(from.Object)
+	if err != nil {
+		return []string{fmt.Sprintf("error rendering yaml: %v", err)}
+	}
+	b, err := toYAML(to.Object)
+	if err != nil {
+		return []string{fmt.Sprintf("error rendering yaml: %v", err)}
+	}
+
+	d := diff.Unified(
+		strings.Split(a, "\n"),
+		strings.Split(b, "\n"),
+		revisionName(from),
+		revisionName(to),
+		3,
+	)
+	if d == "" {
+		return []string{"no differences between " + revisionName(from) + " and " + revisionName(to)}
+	}
+
+	lines := strings.Split(strings.TrimSuffix(d, "\n"), "\n")
+	for i, l := range lines {
+		switch {
+		case strings.HasPrefix(l, "+++"), strings.HasPrefix(l, "---"):
+			lines[i] = lipgloss.NewStyle().Bold(true).Render(l)
+		case strings.HasPrefix(l, "@@"):
+			lines[i] = diffHunkStyle.Render(l)
+		case strings.HasPrefix(l, "+"):
+			lines[i] = diffAddStyle.Render(l)
+		case strings.HasPrefix(l, "-"):
+			lines[i] = diffDelStyle.Render(l)
+		}
+	}
+	return lines
+}
+
+// revision returns the index of the revision with resourceVersion rv in the
+// history, the oldest kept when it was dropped from the history.
+func (m resourceViewModel) revision(rv string) int {
+	for i, r := range m.history {
+		if r.ResourceVersion == rv {
+			return i
+		}
+	}
+	return 0
+}
+
+func revisionName(r models.Revision) string {
+	rv := r.ResourceVersion
+	if rv == "" {
+		rv = "-"
+	}
+	return fmt.Sprintf("rv %s (%s)", rv, r.ObservedAt.Format("15:04:05"))
+}
+
+// markChanged prefixes every rendered line with a gutter, highlighting the
+// lines that are marked as changed.
+func markChanged(rendered string, changed []bool) []string {
+	lines := strings.Split(rendered, "\n")
+	for i := range lines {
+		if i < len(changed) && changed[i] {
+			lines[i] = changedStyle.Render("▌ ") + lines[i]
+		} else {
+			lines[i] = "  " + lines[i]
+		}
+	}
+	return lines
+}
+
 func toYAML(u *unstructured.Unstructured) (string, error) {
 	if u == nil {
 		return "The resource was not found", nil
@@ -135,6 +336,10 @@ func toYAML(u *unstructured.Unstructured) (string, error) {
 }
 
 func highlightYAML(s string) string {
+	if syntaxStyle == "" {
+		return s
+	}
+
 	lexer := lexers.Get("yaml")
 	if lexer == nil {
 		return s
@@ -145,7 +350,7 @@ func highlightYAML(s string) string {
 		return s
 	}
 
-	style := styles.Get("friendly")
+	style := styles.Get(syntaxStyle)
 	if style == nil {
 		style = styles.Fallback
 	}
@@ -19,8 +19,10 @@ func (v debugFlag) BeforeApply(ctx *kong.Context) error {
 // the top-level cli
 type cli struct {
 	// subcommands
-	ViewCmd view.Cmd `cmd:"" name:"view" help:"display subresources"`
-	K9sCmd  k9s.Cmd  `cmd:"" name:"k9s" help:""`
+	ViewCmd     view.Cmd         `cmd:"" name:"view" help:"display subresources"`
+	DiagnoseCmd view.DiagnoseCmd `cmd:"" name:"diagnose" help:"report problems in the subresources of a resource"`
+	OverviewCmd view.OverviewCmd `cmd:"" name:"overview" help:"list the composite resources, claims, Kustomizations and Applications of the cluster with their health"`
+	K9sCmd      k9s.Cmd          `cmd:"" name:"k9s" help:""`
 
 	// flags
 	Debug debugFlag `help:"Enable debug logging"`